@@ -5,7 +5,6 @@ package cmd
 
 import (
 	"fmt"
-	"slices"
 
 	"github.com/charmbracelet/huh"
 	"github.com/mini-clis/shared/custom_errors"
@@ -16,11 +15,17 @@ import (
 )
 
 const UI = "ui"
+const TAG = "tag"
+const PARENT = "parent"
+const REPEAT = "repeat"
 
 // addCmd represents the add command
 func CreateAddCmd() *cobra.Command {
 
 	priorityFlag := flags.NewUnionFlag(task.AllowedProrities, PRIORITY)
+	statusFlag := flags.NewEmptyStringFlag(STATUS)
+	dueFlag := flags.NewDateFlag(DUE)
+	repeatFlag := flags.NewRepeatFlag(REPEAT)
 
 	command := &cobra.Command{
 		Use:   "add",
@@ -29,7 +34,15 @@ func CreateAddCmd() *cobra.Command {
     When you do you must supply a title for your task. you decide to store a task you can set other things using flags.
     The first argument will be the task title the second is the description.
     You can decide a priority by passing in the --priority flag.
+    New tasks are todo unless you pass another status to the --status flag.
+    You can decide when it's due by passing in the --due flag.
+    You can tag it by passing in the --tag flag as many times as you like.
+    You can make it a subtask by passing the id of another task to the --parent flag.
+    Subtasks go in the same list as their parent and other tasks go in the active list.
+    You can make it repeat by passing a rule like daily, weekly on mon/wed, monthly on the 1st
+    or every 3 days after completion to the --repeat flag.
     `,
+		SilenceUsage: true,
 		Args: func(cmd *cobra.Command, args []string) error {
 			ui, error := cmd.Flags().GetBool(UI)
 
@@ -52,13 +65,19 @@ func CreateAddCmd() *cobra.Command {
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			tasks, error := task.ReadTasks()
+			out, error := commandOutput(cmd)
 
 			if error != nil {
 				return error
 			}
 
-			var title, description string
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			var title, description, due, tags string
 			priority := priorityFlag.String()
 
 			ui, _ := cmd.Flags().GetBool(UI)
@@ -87,6 +106,8 @@ func CreateAddCmd() *cobra.Command {
 								func(item string, index int) huh.Option[string] {
 									return huh.NewOption(lo.Capitalize(item), item)
 								})...),
+						dueDateInput(&due),
+						tagsInput(&tags),
 					),
 				)
 
@@ -116,10 +137,105 @@ func CreateAddCmd() *cobra.Command {
 				newTask.Priority = parsedPriority
 			}
 
-			if error := task.SaveTasks(slices.Insert(tasks, 0, newTask)); error != nil {
+			if status := statusFlag.String(); status != "" {
+
+				workflow, error := taskWorkflow()
+
+				if error != nil {
+					return error
+				}
+
+				newTask.Status, error = workflow.Parse(status)
+
+				if error != nil {
+					return error
+				}
+			}
+
+			newTask.Due = dueFlag.Value()
+			newTask.Repeat = repeatFlag.Value()
+
+			tagFlagValues, error := cmd.Flags().GetStringArray(TAG)
+
+			if error != nil {
+				return error
+			}
+
+			newTask.Tags, error = task.ParseTags(tagFlagValues)
+
+			if error != nil {
+				return error
+			}
+
+			if ui {
+
+				newTask.Due, error = parseDueDate(due)
+
+				if error != nil {
+					return error
+				}
+
+				newTask.Tags, error = parseTagList(tags)
+
+				if error != nil {
+					return error
+				}
+			}
+
+			newTask.List, error = activeList(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			newTask.ParentId, error = cmd.Flags().GetString(PARENT)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			if newTask.ParentId != "" {
+
+				parentTask, error := resolveTask(cmd, store, newTask.ParentId)
+
+				if error != nil {
+					return error
+				}
+
+				newTask.ParentId = parentTask.Id()
+				newTask.List = parentTask.List
+			}
+
+			if error := store.Put(newTask); error != nil {
 				return error
 			}
 
+			// The store numbers the task so it's read back to show the number.
+			newTask, error = store.Get(newTask.Id())
+
+			if error != nil {
+				return error
+			}
+
+			if out.passed() {
+
+				tasks, error := store.Load()
+
+				if error != nil {
+					return error
+				}
+
+				return out.print(cmd.OutOrStdout(), printedTasks{tasks: []task.Task{newTask}, all: tasks}, true)
+			}
+
 			plain, error := cmd.Flags().GetBool(PLAIN)
 
 			if error != nil {
@@ -154,13 +270,30 @@ func CreateAddCmd() *cobra.Command {
 
 	command.Flags().VarP(&priorityFlag, PRIORITY, "p", "Decide the priority of a task")
 
+	command.Flags().Var(&statusFlag, STATUS, "Start the task in a status like doing or blocked")
+	command.RegisterFlagCompletionFunc(STATUS, completeStatuses)
+
 	command.Flags().Bool(UI, false, "Render a ui for creating a tasks instead of passing arguments")
 
 	command.RegisterFlagCompletionFunc(PRIORITY, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return task.AllowedProrities, cobra.ShellCompDirectiveDefault
 	})
 
+	command.Flags().Var(&dueFlag, DUE, "Decide when a task is due like tomorrow 17:00, next fri, in 3 days or YYYY-MM-DD")
+
 	command.MarkFlagsMutuallyExclusive(UI, PRIORITY)
+	command.Flags().StringArray(TAG, nil, "Tag the task. Pass it more than once for more tags")
+
+	command.RegisterFlagCompletionFunc(TAG, completeTags)
+
+	command.Flags().String(PARENT, "", "Make the task a subtask of the task with this id")
+	command.Flags().Var(&repeatFlag, REPEAT, "Repeat the task like daily, weekly on mon/wed, monthly on the 1st or every 3 days after completion")
+
+	command.MarkFlagsMutuallyExclusive(UI, DUE)
+	command.MarkFlagsMutuallyExclusive(UI, TAG)
+	command.MarkFlagsMutuallyExclusive(UI, REPEAT)
+
+	printsTasks(command)
 
 	return command
 }
@@ -0,0 +1,467 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// CreateBoardCommand represents the board command
+func CreateBoardCommand() *cobra.Command {
+
+	boardCmd := &cobra.Command{
+		Use:   "board",
+		Short: "Move your tasks between statuses on a board",
+		Long: `Opens the tasks in the active list on a board with a column for every status.
+The columns are todo, doing, blocked and done with any extra statuses from the config before done.
+Pick a column with the arrow keys or tab and a task in it with j and k.
+Press h and l to move the selected task to the previous or next status.
+Tasks can also be dragged onto another column with the mouse.
+Every move is saved to the task store straight away and can be undone with task-list undo.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			board, error := NewTaskBoard(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			_, error = tea.NewProgram(board, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+
+			return error
+		},
+	}
+
+	return boardCmd
+}
+
+type boardKeyMap struct {
+	Up        key.Binding
+	Down      key.Binding
+	Left      key.Binding
+	Right     key.Binding
+	MoveLeft  key.Binding
+	MoveRight key.Binding
+	Help      key.Binding
+	Quit      key.Binding
+}
+
+var boardKeys = boardKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Left: key.NewBinding(
+		key.WithKeys("left", "shift+tab"),
+		key.WithHelp("←", "previous column"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys("right", "tab"),
+		key.WithHelp("→", "next column"),
+	),
+	MoveLeft: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "move back"),
+	),
+	MoveRight: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "move on"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+func (self boardKeyMap) ShortHelp() []key.Binding {
+
+	return []key.Binding{self.MoveLeft, self.MoveRight, self.Left, self.Right, self.Help, self.Quit}
+}
+
+func (self boardKeyMap) FullHelp() [][]key.Binding {
+
+	return [][]key.Binding{
+		{self.Up, self.Down, self.Left, self.Right},
+		{self.MoveLeft, self.MoveRight},
+		{self.Help, self.Quit},
+	}
+}
+
+// board is the model of the board command.
+// Tasks with a status that isn't in the workflow anymore get a column after the workflow's.
+type board struct {
+	store    browserStore
+	list     string
+	workflow task.Workflow
+	statuses []task.Status
+	all      []task.Task
+	columns  [][]task.Task
+	column   int
+	row      int
+	width    int
+	height   int
+	dragging string
+	message  string
+	help     help.Model
+	zones    *zone.Manager
+}
+
+// NewTaskBoard opens the tasks in the active list in a model the board command runs.
+func NewTaskBoard(cmd *cobra.Command) (tea.Model, error) {
+
+	store, journal, error := storeAndJournal(cmd)
+
+	if error != nil {
+		return nil, error
+	}
+
+	list, error := activeList(cmd)
+
+	if error != nil {
+		return nil, error
+	}
+
+	workflow, error := taskWorkflow()
+
+	if error != nil {
+		return nil, error
+	}
+
+	model := board{
+		store:    browserStore{cmd: cmd, store: store, journal: journal},
+		list:     list,
+		workflow: workflow,
+		width:    100,
+		height:   24,
+		help:     help.New(),
+		zones:    zone.New(),
+	}
+
+	return model.reload(), nil
+}
+
+func (self board) Init() tea.Cmd {
+
+	return nil
+}
+
+// reload reads the store again keeping the same task selected when it's still there.
+func (self board) reload() board {
+
+	selected, hasSelected := self.selected()
+
+	tasks, error := self.store.load()
+
+	if error != nil {
+		self.message = error.Error()
+		return self
+	}
+
+	self.all = tasks
+
+	self = self.refresh()
+
+	if hasSelected {
+		return self.selectTask(selected.Id())
+	}
+
+	return self
+}
+
+// refresh puts every task in the list in the column of its status.
+// Each column shows the most important tasks first and then the oldest.
+func (self board) refresh() board {
+
+	tasks := task.InList(self.all, self.list)
+
+	extras := lo.Uniq(lo.FilterMap(tasks, func(item task.Task, index int) (task.Status, bool) {
+		return item.Status, !slices.Contains(self.workflow, item.Status)
+	}))
+
+	slices.Sort(extras)
+
+	self.statuses = append(slices.Clone(self.workflow), extras...)
+
+	self.columns = lo.Map(self.statuses, func(status task.Status, index int) []task.Task {
+
+		column := lo.Filter(tasks, func(item task.Task, index int) bool { return item.Status == status })
+
+		slices.SortStableFunc(column, func(a, b task.Task) int {
+			return cmp.Or(cmp.Compare(b.Priority.Order(), a.Priority.Order()), cmp.Compare(a.CreatedAt(), b.CreatedAt()))
+		})
+
+		return column
+	})
+
+	return self.pick(self.column, self.row)
+}
+
+// pick selects the task at row in column keeping both inside the board.
+func (self board) pick(column, row int) board {
+
+	self.column = lo.Clamp(column, 0, len(self.statuses)-1)
+	self.row = lo.Clamp(row, 0, max(len(self.columns[self.column])-1, 0))
+
+	return self
+}
+
+func (self board) selectTask(id string) board {
+
+	for column, tasks := range self.columns {
+
+		if row := slices.IndexFunc(tasks, func(item task.Task) bool { return item.Id() == id }); row != -1 {
+			return self.pick(column, row)
+		}
+	}
+
+	return self
+}
+
+func (self board) selected() (task.Task, bool) {
+
+	if self.column >= len(self.columns) || self.row >= len(self.columns[self.column]) {
+		return task.Task{}, false
+	}
+
+	return self.columns[self.column][self.row], true
+}
+
+// moveTask saves the task with the status of another column.
+// Moving a task to done completes it like edit --status done would.
+func (self board) moveTask(item task.Task, column int) board {
+
+	if column < 0 || column >= len(self.statuses) || self.statuses[column] == item.Status {
+		return self
+	}
+
+	edited := item
+	edited.Status = self.statuses[column]
+
+	if error := self.store.save("move", edited, item); error != nil {
+		self.message = error.Error()
+		return self.reload()
+	}
+
+	self.message = fmt.Sprintf("Moved %s to %s", item.Title, edited.Status)
+
+	return self.selectTask(item.Id()).reload()
+}
+
+func (self board) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+
+		self.width = msg.Width
+		self.height = msg.Height
+		self.help.Width = msg.Width
+
+	case tea.KeyMsg:
+
+		self.message = ""
+
+		item, hasSelected := self.selected()
+
+		switch {
+		case key.Matches(msg, boardKeys.Quit):
+			return self, tea.Quit
+		case key.Matches(msg, boardKeys.Up):
+			return self.pick(self.column, self.row-1), nil
+		case key.Matches(msg, boardKeys.Down):
+			return self.pick(self.column, self.row+1), nil
+		case key.Matches(msg, boardKeys.Left):
+			return self.pick(self.column-1, self.row), nil
+		case key.Matches(msg, boardKeys.Right):
+			return self.pick(self.column+1, self.row), nil
+		case key.Matches(msg, boardKeys.MoveLeft) && hasSelected:
+			return self.moveTask(item, self.column-1), nil
+		case key.Matches(msg, boardKeys.MoveRight) && hasSelected:
+			return self.moveTask(item, self.column+1), nil
+		case key.Matches(msg, boardKeys.Help):
+			self.help.ShowAll = !self.help.ShowAll
+		}
+
+	case tea.MouseMsg:
+		return self.updateMouse(msg), nil
+	}
+
+	return self, nil
+}
+
+// updateMouse picks up the task pressed on and drops it on the column the button is released over.
+func (self board) updateMouse(msg tea.MouseMsg) board {
+
+	if msg.Button != tea.MouseButtonLeft {
+		return self
+	}
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+
+		for _, tasks := range self.columns {
+			for _, item := range tasks {
+
+				if self.zones.Get(cardZone(item)).InBounds(msg) {
+					self.dragging = item.Id()
+					return self.selectTask(item.Id())
+				}
+			}
+		}
+
+	case tea.MouseActionRelease:
+
+		dragging := self.dragging
+		self.dragging = ""
+
+		item, ok := self.selected()
+
+		if !ok || item.Id() != dragging {
+			return self
+		}
+
+		for column, status := range self.statuses {
+
+			if self.zones.Get(columnZone(status)).InBounds(msg) {
+				return self.moveTask(item, column)
+			}
+		}
+	}
+
+	return self
+}
+
+func cardZone(item task.Task) string {
+
+	return "card-" + item.Id()
+}
+
+func columnZone(status task.Status) string {
+
+	return "column-" + status.Value()
+}
+
+var boardSelectedColumnStyle = browserPaneStyle.BorderForeground(lipgloss.Color("6"))
+
+// columnWidth is the width of a column without its border.
+func (self board) columnWidth() int {
+
+	return max(self.width/max(len(self.statuses), 1)-2, 16)
+}
+
+// columnHeight is the height of a column without its border.
+func (self board) columnHeight() int {
+
+	return max(self.height-lipgloss.Height(self.footer())-3, 4)
+}
+
+func (self board) header() string {
+
+	return browserTitleStyle.Render(fmt.Sprintf("%s (%d)", self.list, len(task.InList(self.all, self.list)))) +
+		browserMutedStyle.Render(" h and l move the selected task")
+}
+
+func (self board) footer() string {
+
+	if self.message == "" {
+		return self.help.View(boardKeys)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, self.message, self.help.View(boardKeys))
+}
+
+// columnView draws the cards that fit in a column.
+// The selected column scrolls so its selected card is always shown.
+func (self board) columnView(column int, listing task.Listing, now time.Time) string {
+
+	status := self.statuses[column]
+	tasks := self.columns[column]
+	width := self.columnWidth()
+
+	style := lo.Ternary(column == self.column, boardSelectedColumnStyle, browserPaneStyle).
+		Width(width).
+		Height(self.columnHeight())
+
+	// Every card is a title, a line about it and a blank line.
+	fits := max((self.columnHeight()-2)/3, 1)
+
+	offset := 0
+
+	if column == self.column && self.row >= fits {
+		offset = self.row - fits + 1
+	}
+
+	lines := []string{
+		statusStyles[status.Value()].Bold(true).Render(fmt.Sprintf("%s %d", strings.ToUpper(status.Value()), len(tasks))),
+		"",
+	}
+
+	for index, item := range tasks[offset:min(offset+fits, len(tasks))] {
+
+		title := lipgloss.NewStyle().MaxWidth(width).Render(item.Title)
+
+		switch {
+		case column == self.column && offset+index == self.row:
+			title = browserSelectedStyle.Render(title)
+		case item.Overdue(now):
+			title = browserOverdueStyle.Render(title)
+		}
+
+		about := browserMutedStyle.Render(listing.ShortId(item)) + " " + priorityBadge(item)
+
+		if item.Due != nil {
+			about += " " + browserMutedStyle.Render(formatDueDate(item.Due))
+		}
+
+		lines = append(lines, self.zones.Mark(cardZone(item), title+"\n"+about), "")
+	}
+
+	return self.zones.Mark(columnZone(status), style.Render(strings.Join(lines, "\n")))
+}
+
+func (self board) View() string {
+
+	listing := task.NewListing(self.all)
+
+	now := time.Now()
+
+	columns := lo.Map(self.statuses, func(status task.Status, column int) string {
+		return self.columnView(column, listing, now)
+	})
+
+	return self.zones.Scan(lipgloss.JoinVertical(
+		lipgloss.Left,
+		self.header(),
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+		self.footer(),
+	))
+}
+
+func init() {
+	rootCmd.AddCommand(CreateBoardCommand())
+}
@@ -0,0 +1,715 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+type browserMode int
+
+const (
+	browsing browserMode = iota
+	filtering
+	editing
+	confirmingDelete
+)
+
+// browserSort is an order the browser can show tasks in.
+type browserSort struct {
+	name    string
+	compare func(a, b task.Task) int
+}
+
+var browserSorts = []browserSort{
+	{"newest", func(a, b task.Task) int { return cmp.Compare(b.CreatedAt(), a.CreatedAt()) }},
+	{"priority", func(a, b task.Task) int { return cmp.Compare(b.Priority.Order(), a.Priority.Order()) }},
+	{"due", func(a, b task.Task) int {
+		switch {
+		case a.Due == nil && b.Due == nil:
+			return 0
+		case a.Due == nil:
+			return 1
+		case b.Due == nil:
+			return -1
+		}
+		return a.Due.Compare(*b.Due)
+	}},
+	{"title", func(a, b task.Task) int { return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) }},
+}
+
+type browserKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Top    key.Binding
+	Bottom key.Binding
+	Toggle key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Filter key.Binding
+	Sort   key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+}
+
+var browserKeys = browserKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g", "first task"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "last task"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "complete"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e", "enter"),
+		key.WithHelp("e", "edit"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d", "x"),
+		key.WithHelp("d", "delete"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "sort"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+func (self browserKeyMap) ShortHelp() []key.Binding {
+
+	return []key.Binding{self.Toggle, self.Edit, self.Delete, self.Filter, self.Sort, self.Help, self.Quit}
+}
+
+func (self browserKeyMap) FullHelp() [][]key.Binding {
+
+	return [][]key.Binding{
+		{self.Up, self.Down, self.Top, self.Bottom},
+		{self.Toggle, self.Edit, self.Delete},
+		{self.Filter, self.Sort, self.Help, self.Quit},
+	}
+}
+
+// browserEdit holds what's typed into the edit form.
+// The form writes to it through pointers so it lives outside the model.
+type browserEdit struct {
+	task                    task.Task
+	title, description, due string
+	priority, tags          string
+	confirmed               bool
+}
+
+// browser is the model of the tui command.
+// It keeps every task so blockers in other lists can be shown.
+type browser struct {
+	store   browserStore
+	list    string
+	all     []task.Task
+	visible []task.Task
+	cursor  int
+	offset  int
+	width   int
+	height  int
+	sort    int
+	mode    browserMode
+	filter  textinput.Model
+	form    *huh.Form
+	edit    *browserEdit
+	message string
+	help    help.Model
+	zones   *zone.Manager
+}
+
+// NewTaskBrowser opens the tasks in the active list in a model the tui command runs.
+func NewTaskBrowser(cmd *cobra.Command) (tea.Model, error) {
+
+	store, journal, error := storeAndJournal(cmd)
+
+	if error != nil {
+		return nil, error
+	}
+
+	list, error := activeList(cmd)
+
+	if error != nil {
+		return nil, error
+	}
+
+	filter := textinput.New()
+
+	filter.Prompt = "/"
+	filter.Placeholder = "filter by title, description or tag"
+
+	model := browser{
+		store:  browserStore{cmd: cmd, store: store, journal: journal},
+		list:   list,
+		width:  80,
+		height: 24,
+		filter: filter,
+		help:   help.New(),
+		zones:  zone.New(),
+	}
+
+	return model.reload(), nil
+}
+
+func (self browser) Init() tea.Cmd {
+
+	return nil
+}
+
+// reload reads the store again keeping the same task selected when it's still there.
+func (self browser) reload() browser {
+
+	selected, hasSelected := self.selected()
+
+	tasks, error := self.store.load()
+
+	if error != nil {
+		self.message = error.Error()
+		return self
+	}
+
+	self.all = tasks
+
+	self = self.refresh()
+
+	if hasSelected {
+
+		if index := lo.IndexOf(lo.Map(self.visible, func(item task.Task, index int) string { return item.Id() }), selected.Id()); index != -1 {
+			self.cursor = index
+		}
+	}
+
+	return self.scroll()
+}
+
+// refresh works out which tasks are shown and in what order.
+func (self browser) refresh() browser {
+
+	visible := task.InList(self.all, self.list)
+
+	if terms := task.SearchTerms(self.filter.Value()); len(terms) > 0 {
+
+		matches := lo.SliceToMap(task.Search(visible, terms), func(result task.SearchResult) (string, bool) {
+			return result.Id(), true
+		})
+
+		visible = lo.Filter(visible, func(item task.Task, index int) bool { return matches[item.Id()] })
+	}
+
+	slices.SortStableFunc(visible, browserSorts[self.sort].compare)
+
+	self.visible = visible
+	self.cursor = lo.Clamp(self.cursor, 0, max(len(visible)-1, 0))
+
+	return self.scroll()
+}
+
+// scroll keeps the selected task inside the list pane.
+func (self browser) scroll() browser {
+
+	rows := self.listHeight()
+
+	if self.cursor < self.offset {
+		self.offset = self.cursor
+	}
+
+	if self.cursor >= self.offset+rows {
+		self.offset = self.cursor - rows + 1
+	}
+
+	self.offset = lo.Clamp(self.offset, 0, max(len(self.visible)-rows, 0))
+
+	return self
+}
+
+func (self browser) selected() (task.Task, bool) {
+
+	if self.cursor < 0 || self.cursor >= len(self.visible) {
+		return task.Task{}, false
+	}
+
+	return self.visible[self.cursor], true
+}
+
+func (self browser) move(to int) browser {
+
+	self.cursor = lo.Clamp(to, 0, max(len(self.visible)-1, 0))
+
+	return self.scroll()
+}
+
+func (self browser) toggle(item task.Task) browser {
+
+	edited := item.MarkComplete(!item.Done())
+
+	if error := self.store.save(lo.Ternary(edited.Done(), "complete", "reopen"), edited, item); error != nil {
+		self.message = error.Error()
+		return self.reload()
+	}
+
+	self.message = fmt.Sprintf("%s %s", lo.Ternary(edited.Done(), "Completed", "Reopened"), item.Title)
+
+	return self.reload()
+}
+
+func (self browser) formWidth() int {
+
+	return max(self.width-self.listWidth()-4, 20)
+}
+
+func (self browser) startEdit(item task.Task) (browser, tea.Cmd) {
+
+	self.edit = &browserEdit{
+		task:        item,
+		title:       item.Title,
+		description: item.Description,
+		priority:    item.Priority.Value(),
+		due:         formatDueDate(item.Due),
+		tags:        strings.Join(item.Tags, ", "),
+	}
+
+	self.form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Title").
+				CharLimit(80).
+				Value(&self.edit.title).
+				Validate(huh.ValidateNotEmpty()),
+			huh.NewText().
+				Title("Description").
+				CharLimit(80).
+				Lines(2).
+				Value(&self.edit.description),
+			huh.NewSelect[string]().
+				Title("Priority").
+				Value(&self.edit.priority).
+				Options(
+					huh.NewOption("Low", "low"),
+					huh.NewOption("Medium", "medium"),
+					huh.NewOption("High", "high"),
+				),
+			dueDateInput(&self.edit.due),
+			tagsInput(&self.edit.tags),
+		),
+	).WithShowHelp(false).WithWidth(self.formWidth())
+
+	self.mode = editing
+
+	return self, self.form.Init()
+}
+
+func (self browser) finishEdit() browser {
+
+	values := self.edit
+
+	edited := values.task
+	edited.Title = values.title
+	edited.Description = values.description
+
+	priority, priorityError := task.ParsePriority(values.priority)
+	due, dueError := parseDueDate(values.due)
+	tags, tagsError := parseTagList(values.tags)
+
+	if error := lo.FirstOrEmpty(lo.Compact([]error{priorityError, dueError, tagsError})); error != nil {
+		self.message = error.Error()
+		return self
+	}
+
+	edited.Priority = priority
+	edited.Due = due
+	edited.Tags = lo.Ternary(len(tags) == 0, nil, tags)
+
+	if error := self.store.save("edit", edited, values.task); error != nil {
+		self.message = error.Error()
+		return self.reload()
+	}
+
+	self.message = fmt.Sprintf("Saved %s", edited.Title)
+
+	return self.reload()
+}
+
+func (self browser) startDelete(item task.Task) (browser, tea.Cmd) {
+
+	self.edit = &browserEdit{task: item}
+
+	self.form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Delete %s?", item.Title)).
+				Description("Its subtasks move up to its parent").
+				Affirmative("Delete").
+				Negative("Keep").
+				Value(&self.edit.confirmed),
+		),
+	).WithShowHelp(false).WithWidth(self.formWidth())
+
+	self.mode = confirmingDelete
+
+	return self, self.form.Init()
+}
+
+func (self browser) finishDelete() browser {
+
+	if !self.edit.confirmed {
+		return self
+	}
+
+	if error := self.store.delete(self.edit.task); error != nil {
+		self.message = error.Error()
+		return self.reload()
+	}
+
+	self.message = fmt.Sprintf("Deleted %s", self.edit.task.Title)
+
+	return self.reload()
+}
+
+func (self browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+
+		self.width = size.Width
+		self.height = size.Height
+		self.help.Width = size.Width
+
+		if self.form != nil {
+			self.form = self.form.WithWidth(self.formWidth())
+		}
+
+		return self.scroll(), nil
+	}
+
+	switch self.mode {
+	case editing, confirmingDelete:
+		return self.updateForm(msg)
+	case filtering:
+		return self.updateFilter(msg)
+	}
+
+	switch msg := msg.(type) {
+
+	case tea.KeyMsg:
+
+		self.message = ""
+
+		item, hasSelected := self.selected()
+
+		switch {
+		case key.Matches(msg, browserKeys.Quit):
+			return self, tea.Quit
+		case key.Matches(msg, browserKeys.Up):
+			return self.move(self.cursor - 1), nil
+		case key.Matches(msg, browserKeys.Down):
+			return self.move(self.cursor + 1), nil
+		case key.Matches(msg, browserKeys.Top):
+			return self.move(0), nil
+		case key.Matches(msg, browserKeys.Bottom):
+			return self.move(len(self.visible) - 1), nil
+		case key.Matches(msg, browserKeys.Sort):
+			self.sort = (self.sort + 1) % len(browserSorts)
+			return self.refresh(), nil
+		case key.Matches(msg, browserKeys.Filter):
+			self.mode = filtering
+			return self, self.filter.Focus()
+		case key.Matches(msg, browserKeys.Help):
+			self.help.ShowAll = !self.help.ShowAll
+			return self.scroll(), nil
+		case key.Matches(msg, browserKeys.Toggle) && hasSelected:
+			return self.toggle(item), nil
+		case key.Matches(msg, browserKeys.Edit) && hasSelected:
+			return self.startEdit(item)
+		case key.Matches(msg, browserKeys.Delete) && hasSelected:
+			return self.startDelete(item)
+		}
+
+	case tea.MouseMsg:
+		return self.updateMouse(msg), nil
+	}
+
+	return self, nil
+}
+
+// updateForm passes everything to the edit or delete form until it's done.
+// Escape leaves the form without saving.
+func (self browser) updateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "esc" {
+		self.mode = browsing
+		self.form = nil
+		return self, nil
+	}
+
+	model, formCmd := self.form.Update(msg)
+
+	self.form = model.(*huh.Form)
+
+	switch self.form.State {
+	case huh.StateCompleted:
+		mode := self.mode
+		self.mode = browsing
+		self.form = nil
+		return lo.Ternary(mode == editing, self.finishEdit, self.finishDelete)(), nil
+	case huh.StateAborted:
+		self.mode = browsing
+		self.form = nil
+		return self, nil
+	}
+
+	return self, formCmd
+}
+
+// updateFilter narrows the tasks as the filter is typed.
+// Enter keeps the filter and escape clears it.
+func (self browser) updateFilter(msg tea.Msg) (tea.Model, tea.Cmd) {
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+
+		switch keyMsg.String() {
+		case "enter":
+			self.mode = browsing
+			self.filter.Blur()
+			return self, nil
+		case "esc":
+			self.mode = browsing
+			self.filter.Blur()
+			self.filter.SetValue("")
+			return self.refresh(), nil
+		}
+	}
+
+	var filterCmd tea.Cmd
+
+	self.filter, filterCmd = self.filter.Update(msg)
+
+	self.cursor = 0
+
+	return self.refresh(), filterCmd
+}
+
+// updateMouse selects the task that was clicked and completes it when its checkbox was.
+// The wheel moves the selection.
+func (self browser) updateMouse(msg tea.MouseMsg) browser {
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return self.move(self.cursor - 1)
+	case tea.MouseButtonWheelDown:
+		return self.move(self.cursor + 1)
+	}
+
+	if msg.Action != tea.MouseActionRelease || msg.Button != tea.MouseButtonLeft {
+		return self
+	}
+
+	for index, item := range self.visible {
+
+		if self.zones.Get(checkboxZone(item)).InBounds(msg) {
+			return self.move(index).toggle(item)
+		}
+
+		if self.zones.Get(rowZone(item)).InBounds(msg) {
+			return self.move(index)
+		}
+	}
+
+	return self
+}
+
+func checkboxZone(item task.Task) string {
+
+	return "toggle-" + item.Id()
+}
+
+func rowZone(item task.Task) string {
+
+	return "task-" + item.Id()
+}
+
+var (
+	browserTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	browserSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("8"))
+	browserDoneStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Strikethrough(true)
+	browserOverdueStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	browserMutedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	browserPaneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+)
+
+// listWidth is the width of the list pane without its border.
+func (self browser) listWidth() int {
+
+	return max(self.width*3/5-2, 20)
+}
+
+// listHeight is how many tasks fit in the list pane.
+func (self browser) listHeight() int {
+
+	return max(self.height-lipgloss.Height(self.footer())-4, 1)
+}
+
+func (self browser) header() string {
+
+	header := browserTitleStyle.Render(fmt.Sprintf("%s (%d)", self.list, len(self.visible))) +
+		browserMutedStyle.Render(fmt.Sprintf(" sorted by %s", browserSorts[self.sort].name))
+
+	if self.mode == filtering || self.filter.Value() != "" {
+		header += "  " + self.filter.View()
+	}
+
+	return header
+}
+
+func (self browser) footer() string {
+
+	if self.message == "" {
+		return self.help.View(browserKeys)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, self.message, self.help.View(browserKeys))
+}
+
+func (self browser) listPane() string {
+
+	width := self.listWidth()
+
+	if len(self.visible) == 0 {
+		return browserPaneStyle.Width(width).Height(self.listHeight()).Render(browserMutedStyle.Render("There are no tasks here"))
+	}
+
+	now := time.Now()
+
+	rows := lo.Map(self.visible[self.offset:min(self.offset+self.listHeight(), len(self.visible))], func(item task.Task, index int) string {
+
+		checkbox := lo.Ternary(item.Done(), "[x]", "[ ]")
+
+		title := lipgloss.NewStyle().MaxWidth(width - 5).Render(item.Title)
+
+		switch {
+		case self.offset+index == self.cursor:
+			title = browserSelectedStyle.Render(title)
+		case item.Done():
+			title = browserDoneStyle.Render(title)
+		case item.Overdue(now):
+			title = browserOverdueStyle.Render(title)
+		}
+
+		return self.zones.Mark(checkboxZone(item), checkbox) + " " + self.zones.Mark(rowZone(item), title)
+	})
+
+	return browserPaneStyle.Width(width).Height(self.listHeight()).Render(strings.Join(rows, "\n"))
+}
+
+func (self browser) detailPane() string {
+
+	width := max(self.width-self.listWidth()-4, 20)
+
+	pane := browserPaneStyle.Width(width).Height(self.listHeight())
+
+	if self.form != nil {
+		return pane.Render(self.form.View())
+	}
+
+	item, ok := self.selected()
+
+	if !ok {
+		return pane.Render("")
+	}
+
+	listing := task.NewListing(self.all)
+
+	now := time.Now()
+
+	field := func(name, value string) string {
+		return browserMutedStyle.Render(fmt.Sprintf("%-9s", name)) + value
+	}
+
+	lines := []string{
+		browserTitleStyle.Render(item.Title),
+		"",
+		field("id", fmt.Sprintf("%s #%d", listing.ShortId(item), item.Number())),
+		field("priority", priorityBadge(item)),
+		field("status", taskStatus(item, listing, now)),
+	}
+
+	if item.Due != nil {
+		lines = append(lines, field("due", formatDueDate(item.Due)))
+	}
+
+	if len(item.Tags) > 0 {
+		lines = append(lines, field("tags", strings.Join(item.Tags, ", ")))
+	}
+
+	if item.Repeat != nil {
+		lines = append(lines, field("repeats", item.Repeat.Describe()))
+	}
+
+	if parent, ok := lo.Find(self.all, func(other task.Task) bool { return other.Id() == item.ParentId }); ok {
+		lines = append(lines, field("parent", parent.Title))
+	}
+
+	if blockers := item.Blockers(self.all); len(blockers) > 0 {
+		lines = append(lines, field("waits on", strings.Join(lo.Map(blockers, func(blocker task.Task, index int) string {
+			return blocker.Title
+		}), ", ")))
+	}
+
+	lines = append(
+		lines,
+		field("created", relativeTime(time.UnixMicro(item.CreatedAt()), now)),
+		field("updated", relativeTime(item.UpdatedAt, now)),
+	)
+
+	if item.Description != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Width(width).Render(item.Description))
+	}
+
+	return pane.Render(strings.Join(lines, "\n"))
+}
+
+func (self browser) View() string {
+
+	return self.zones.Scan(lipgloss.JoinVertical(
+		lipgloss.Left,
+		self.header(),
+		lipgloss.JoinHorizontal(lipgloss.Top, self.listPane(), self.detailPane()),
+		self.footer(),
+	))
+}
@@ -7,8 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/query"
 	"github.com/mini-clis/task-list/task"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
@@ -16,31 +19,73 @@ import (
 
 const COMPLETION = "completion"
 const INCOMPLETE = "incomplete"
+const REVISION = "revision"
+const CHILDREN = "children"
+const REPARENT = "reparent"
+const CASCADE = "cascade"
 
 var allowedCompletionValues = []string{
 	COMPLETE,
 	INCOMPLETE,
 }
 
+var allowedChildrenValues = []string{
+	REPARENT,
+	CASCADE,
+}
+
 // deleteCmd represents the delete command
 func CreateDeleteCommand() *cobra.Command {
+
+	childrenFlag := flags.NewUnionFlag(allowedChildrenValues, CHILDREN)
+
 	var deleteCmd = &cobra.Command{
-		Use:   "delete",
+		Use:   "delete <id>",
 		Short: "Delete a task based on an id",
-		Long: `You can delete a task based on an Id.
+		Long: `You can delete a task based on an Id, the start of its id or its number in the list.
 The flags in this command allow you to pass in
 a title or delete tasks with specific properties.
+Pass --where with a query instead of an id to delete every task in the list it matches.
+Subtasks of a deleted task move up to its parent unless you pass --children cascade
+which deletes them too.
 `,
-		SilenceUsage: true,
-		Args:         cobra.ExactArgs(1),
+		SilenceUsage:      true,
+		Args:              idOrWhere,
+		ValidArgsFunction: completeTaskIds,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			tasks, error := task.ReadTasks()
+			where, hasWhere, error := whereQuery(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			out, error := commandOutput(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
 
 			if error != nil {
 				return error
 			}
 
-			firstArgument := args[0]
+			defer unlock()
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			firstArgument := lo.FirstOrEmpty(args)
 
 			title, titleError := cmd.Flags().GetBool(TITLE)
 			completion, completionError := cmd.Flags().GetBool(COMPLETION)
@@ -75,43 +120,129 @@ a title or delete tasks with specific properties.
 				)
 			}
 
+			if !priority && !completion && !title && !hasWhere {
+
+				list, error := activeList(cmd)
+
+				if error != nil {
+					return error
+				}
+
+				// Ambiguous prefixes are reported but an unknown id falls through to the error below.
+				found, error := task.Resolve(tasks, firstArgument, list)
+
+				if errors.Is(error, task.ErrAmbiguousId) {
+					return error
+				}
+
+				if error == nil {
+					firstArgument = found.Id()
+				}
+			}
+
+			// Deleting by id works in any list but the other modes only delete from the active list.
+			scopedTasks := tasks
+
+			if priority || completion || title || hasWhere {
+
+				list, error := activeList(cmd)
+
+				if error != nil {
+					return error
+				}
+
+				scopedTasks = task.InList(tasks, list)
+			}
+
 			filteredTasks := lo.If(
 				priority,
-				lo.Filter(tasks, func(item task.Task, index int) bool {
+				lo.Filter(scopedTasks, func(item task.Task, index int) bool {
 					parsedPriority, _ := task.ParsePriority(firstArgument)
 					return item.Priority != parsedPriority
 				})).
 				ElseIf(
 					completion && firstArgument == COMPLETE,
-					lo.Filter(tasks, func(item task.Task, index int) bool {
-						return item.Complete == true
+					lo.Filter(scopedTasks, func(item task.Task, index int) bool {
+						return item.Done()
 					})).
 				ElseIf(
 					completion && firstArgument == INCOMPLETE,
-					lo.Filter(tasks, func(item task.Task, index int) bool {
-						return item.Complete == false
+					lo.Filter(scopedTasks, func(item task.Task, index int) bool {
+						return !item.Done()
+					})).
+				ElseIf(
+					hasWhere,
+					lo.Reject(scopedTasks, func(item task.Task, index int) bool {
+						return where.Match(item, query.Env{Now: time.Now(), Tasks: tasks})
 					})).
 				ElseIf(
 					title,
-					lo.Filter(tasks, func(item task.Task, index int) bool {
+					lo.Filter(scopedTasks, func(item task.Task, index int) bool {
 						return item.Title != firstArgument
 					})).
 				Else(
-					lo.Filter(tasks, func(item task.Task, index int) bool {
+					lo.Filter(scopedTasks, func(item task.Task, index int) bool {
 						return item.Id() != firstArgument
 					}),
 				)
 
-			if len(tasks) == len(filteredTasks) {
+			if hasWhere && len(scopedTasks) == len(filteredTasks) {
+				return custom_errors.CreateInvalidArgumentErrorWithMessage(
+					fmt.Sprintf("There are no tasks that match %s", where.Input()),
+				)
+			}
+
+			if len(scopedTasks) == len(filteredTasks) {
 				return custom_errors.CreateInvalidArgumentErrorWithMessage(
 					fmt.Sprintf("A task with this id %s doesn't exist", firstArgument),
 				)
 			}
 
-			if error := task.SaveTasks(filteredTasks); error != nil {
+			if cmd.Flags().Changed(REVISION) {
+
+				revision, error := cmd.Flags().GetInt(REVISION)
+
+				if error != nil {
+					return error
+				}
+
+				if _, error := task.CheckRevision(store, firstArgument, revision); error != nil {
+					return error
+				}
+			}
+
+			deletedIds := lo.Without(
+				lo.Map(scopedTasks, func(item task.Task, index int) string { return item.Id() }),
+				lo.Map(filteredTasks, func(item task.Task, index int) string { return item.Id() })...,
+			)
+
+			if childrenFlag.String() == CASCADE {
+
+				deletedIds = lo.Uniq(append(deletedIds, lo.FlatMap(deletedIds, func(id string, index int) []string {
+					return lo.Map(task.Descendants(tasks, id), func(item task.Task, index int) string {
+						return item.Id()
+					})
+				})...))
+			}
+
+			if error := task.SaveChanges(store, task.Reparent(tasks, deletedIds), deletedIds); error != nil {
 				return error
 			}
 
+			if out.passed() {
+
+				deleted := lo.Filter(tasks, func(item task.Task, index int) bool { return lo.Contains(deletedIds, item.Id()) })
+
+				return out.print(cmd.OutOrStdout(), newDeletedTasks(deleted), false)
+			}
+
+			if hasWhere {
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%d tasks were deleted\n", len(deletedIds))
+
+				return nil
+			}
+
 			fmt.Fprintln(
 				cmd.OutOrStdout(),
 				fmt.Sprintf(
@@ -138,6 +269,36 @@ a title or delete tasks with specific properties.
 
 	deleteCmd.MarkFlagsMutuallyExclusive(allowedFlagNames...)
 
+	deleteCmdFlags.String(WHERE, "", whereFlagUsage("Delete"))
+
+	deleteCmdFlags.Int(
+		REVISION,
+		0,
+		"Only delete the task if it's still at this revision",
+	)
+
+	lo.ForEach(allowedFlagNames, func(item string, index int) {
+		deleteCmd.MarkFlagsMutuallyExclusive(item, REVISION)
+		deleteCmd.MarkFlagsMutuallyExclusive(item, WHERE)
+	})
+
+	deleteCmd.MarkFlagsMutuallyExclusive(WHERE, REVISION)
+
+	deleteCmdFlags.Var(
+		&childrenFlag,
+		CHILDREN,
+		fmt.Sprintf("What happens to subtasks of deleted tasks one of %s (default is %s)", strings.Join(allowedChildrenValues, ","), REPARENT),
+	)
+
+	deleteCmd.RegisterFlagCompletionFunc(
+		CHILDREN,
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return allowedChildrenValues, cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	printsTasks(deleteCmd)
+
 	return deleteCmd
 }
 
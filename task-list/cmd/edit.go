@@ -4,13 +4,17 @@ Copyright © 2024 Your Name
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/mini-clis/shared/custom_errors"
 	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/query"
 	"github.com/mini-clis/task-list/task"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
@@ -21,6 +25,14 @@ const (
 	DESCRIPTION = "description"
 	PRIORITY    = "priority"
 	COMPLETE    = "complete"
+	STATUS      = "status"
+	DUE         = "due"
+	ADD_TAG     = "add-tag"
+	REMOVE_TAG  = "remove-tag"
+	FORCE       = "force"
+	MERGE       = "merge"
+	BLOCKED_BY  = "blocked-by"
+	UNBLOCK     = "unblock"
 )
 
 // CreateEditCmd represents the creation of the edit command
@@ -30,57 +42,114 @@ var CreateEditCmd = func() *cobra.Command {
 	descriptionFlag := flags.NewEmptyStringFlag(DESCRIPTION)
 	priorityFlag := flags.NewUnionFlag(task.AllowedProrities, PRIORITY)
 	completeFlag := flags.NewBoolFlag(COMPLETE)
+	statusFlag := flags.NewEmptyStringFlag(STATUS)
+	dueFlag := flags.NewDateFlag(DUE)
+	repeatFlag := flags.NewRepeatFlag(REPEAT)
 
 	editCommand := &cobra.Command{
-		Use:          "edit",
-		Short:        "Edits a task",
-		Args:         cobra.ExactArgs(1),
-		SilenceUsage: true,
-		Long: `A task can be edited by using it's id.
+		Use:               "edit <id>",
+		Short:             "Edits a task",
+		Args:              idOrWhere,
+		SilenceUsage:      true,
+		ValidArgsFunction: completeTaskIds,
+		Long: `A task can be edited by using it's id, the start of its id or its number in the list.
 			When editing a task you can pass in a flag to tell this command which property you want to change.
-			The only ones that are supported are title, description, complete, and priority.
-			If there are no flags passed through then you will see a form allowing you to edit all four of the following props.
+			The only ones that are supported are title, description, status, priority, due, tags and blocked-by.
+			A status is one of todo, doing, blocked, done or an extra status from the config.
+			Pass --complete true or false as a shortcut for --status done or moving a done task back to todo.
+			Pass --due none to remove a due date.
+			Pass --blocked-by with the id of another task to make this one wait for it.
+			Tasks can't block each other in a cycle.
+			Pass --repeat none to stop a task repeating.
+			Completing a repeating task creates its next instance with the due date moved on.
+			If there are no flags passed through then you will see a form allowing you to edit all of the following props.
+			If someone else saved the task while you were editing it nothing is saved unless you pass --force or --merge.
+			Pass --where with a query instead of an id to make the same change to every task in the list it matches.
 		`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			id := args[0]
+			where, hasWhere, err := whereQuery(cmd)
 
-			tasks, err := task.ReadTasks()
+			if err != nil {
+				return err
+			}
+
+			out, err := commandOutput(cmd)
 
 			if err != nil {
 				return err
 			}
 
-			foundTask, ok := lo.Find(tasks, func(task task.Task) bool {
-				return task.Id() == id
-			})
+			store, err := taskStore(cmd)
 
-			if !ok {
-				return fmt.Errorf(
-					"%w Task with this id wasn't found %s",
-					custom_errors.InvalidArgument,
-					id,
-				)
+			if err != nil {
+				return err
 			}
 
 			title := titleFlag.String()
 			description := descriptionFlag.String()
 			priority := priorityFlag.String()
 			complete := completeFlag.String()
+			status := statusFlag.String()
+			due := dueFlag.String()
+			repeat := repeatFlag.String()
+
+			addTags, addTagsErr := cmd.Flags().GetStringArray(ADD_TAG)
+			removeTags, removeTagsErr := cmd.Flags().GetStringArray(REMOVE_TAG)
+			blockedBy, blockedByErr := cmd.Flags().GetStringArray(BLOCKED_BY)
+			unblock, unblockErr := cmd.Flags().GetStringArray(UNBLOCK)
+
+			if err := errors.Join(addTagsErr, removeTagsErr, blockedByErr, unblockErr); err != nil {
+				return err
+			}
 
 			everyFlagValueIsEmpty := lo.EveryBy(
-				[]string{title, description, priority, complete},
+				[]string{title, description, priority, complete, status, due, repeat},
 				func(flagValue string) bool {
 					return flagValue == ""
 				},
-			)
+			) && len(addTags) == 0 && len(removeTags) == 0 && len(blockedBy) == 0 && len(unblock) == 0
+
+			if hasWhere {
+
+				if everyFlagValueIsEmpty {
+					return custom_errors.CreateInvalidFlagErrorWithMessage(
+						WHERE,
+						"needs at least one flag saying what to change in the tasks it matches",
+					)
+				}
+
+				edits := taskEdits{title, description, priority, complete, status, due, repeat, repeatFlag.Value(), addTags, removeTags, blockedBy, unblock}
+
+				return editWhere(cmd, store, where, edits, out)
+			}
+
+			foundTask, err := resolveTask(cmd, store, args[0])
+
+			if err != nil {
+				return err
+			}
+
+			id := foundTask.Id()
+
+			baseTask := foundTask
 
 			if everyFlagValueIsEmpty {
 
+				workflow, err := taskWorkflow()
+
+				if err != nil {
+					return err
+				}
+
 				title = foundTask.Title
 				description = foundTask.Description
 				priority = foundTask.Priority.Value()
-				internalComplete := foundTask.Complete
+				status = foundTask.Status.Value()
+				originalStatus := status
+				originalDue := formatDueDate(foundTask.Due)
+				due = originalDue
+				tags := strings.Join(foundTask.Tags, ", ")
 
 				form := huh.NewForm(
 					huh.NewGroup(
@@ -108,13 +177,9 @@ var CreateEditCmd = func() *cobra.Command {
 								huh.NewOption("Medium", "medium"),
 								huh.NewOption("High", "high"),
 							),
-						huh.NewConfirm().
-							Key("complete").
-							Value(&internalComplete).
-							Description("Is this task complete?").
-							Title("Complete").
-							Affirmative("Yes").
-							Negative("No"),
+						statusInput(&status, workflow),
+						dueDateInput(&due),
+						tagsInput(&tags),
 					),
 				)
 
@@ -122,55 +187,100 @@ var CreateEditCmd = func() *cobra.Command {
 					return err
 				}
 
-				complete = fmt.Sprintf("%t", internalComplete)
+				formTags, err := parseTagList(tags)
 
-			}
+				if err != nil {
+					return err
+				}
+
+				status = lo.Ternary(status == originalStatus, "", status)
+
+				addTags = lo.Without(formTags, foundTask.Tags...)
+				removeTags = lo.Without(foundTask.Tags, formTags...)
+
+				due = lo.If(due == originalDue, "").
+					ElseIf(due == "", flags.NO_DATE).
+					Else(due)
 
-			if title != "" && foundTask.Title != title {
-				foundTask.Title = title
-				foundTask.UpdatedAt = time.Now()
 			}
 
-			if description != "" && foundTask.Description != description {
-				foundTask.Description = description
-				foundTask.UpdatedAt = time.Now()
+			edits := taskEdits{title, description, priority, complete, status, due, repeat, repeatFlag.Value(), addTags, removeTags, blockedBy, unblock}
+
+			foundTask, changed, err := edits.apply(cmd, store, foundTask)
+
+			if err != nil {
+				return err
 			}
 
-			if priority != "" {
-				parsedPriority, err := task.ParsePriority(priority)
+			var createdInstance *task.Task
+
+			if changed {
+
+				foundTask = foundTask.Revise()
+
+				unlock, err := lockStore(cmd, store)
 
 				if err != nil {
 					return err
 				}
 
-				if parsedPriority.Value() != foundTask.Priority.Value() {
-					foundTask.Priority = parsedPriority
-					foundTask.UpdatedAt = time.Now()
-				}
-			}
+				latestTask, err := task.CheckRevision(store, id, baseTask.Revision())
+
+				var conflictError task.ConflictError
+
+				if errors.As(err, &conflictError) {
 
-			if complete != "" {
+					// The merge form waits for you so other processes can use the store while it's open.
+					unlock()
 
-				if strconv.FormatBool(foundTask.Complete) != complete {
+					force, _ := cmd.Flags().GetBool(FORCE)
+					merge, _ := cmd.Flags().GetBool(MERGE)
+
+					foundTask, err = resolveConflict(
+						conflictError,
+						baseTask,
+						foundTask,
+						latestTask,
+						force,
+						merge || everyFlagValueIsEmpty,
+					)
+
+					if err != nil {
+						return err
+					}
 
-					parsedComplete, err := strconv.ParseBool(complete)
+					unlock, err = lockStore(cmd, store)
 
 					if err != nil {
 						return err
 					}
 
-					foundTask.Complete = parsedComplete
+					// Someone may have saved the task again while the form was open.
+					_, err = task.CheckRevision(store, id, latestTask.Revision())
+				}
+
+				defer unlock()
+
+				if err != nil {
+					return err
+				}
+
+				foundTask, createdInstance, err = saveEditedTask(store, foundTask, baseTask)
 
-					foundTask.UpdatedAt = time.Now()
+				if err != nil {
+					return err
 				}
 			}
 
-			updatedTasks := lo.Map(tasks, func(item task.Task, index int) task.Task {
-				return lo.If(item.Id() == foundTask.Id(), foundTask).Else(item)
-			})
+			if out.passed() {
 
-			if err := task.SaveTasks(updatedTasks); err != nil {
-				return err
+				tasks, err := store.Load()
+
+				if err != nil {
+					return err
+				}
+
+				return out.print(cmd.OutOrStdout(), printedTasks{tasks: []task.Task{foundTask}, all: tasks}, true)
 			}
 
 			plain, error := cmd.Flags().GetBool(PLAIN)
@@ -201,6 +311,15 @@ var CreateEditCmd = func() *cobra.Command {
 				taskAsJSON,
 			)
 
+			if createdInstance != nil {
+				fmt.Fprintf(
+					cmd.OutOrStdout(),
+					"The next one is due %s %s\n",
+					formatDueDate(createdInstance.Due),
+					createdInstance.Id(),
+				)
+			}
+
 			return nil
 		},
 	}
@@ -215,11 +334,402 @@ var CreateEditCmd = func() *cobra.Command {
 		},
 	)
 
-	editCommand.Flags().Var(&completeFlag, COMPLETE, "Mark task complete or not")
+	editCommand.Flags().Var(&statusFlag, STATUS, "Move the task to a status like todo, doing, blocked or done")
+	editCommand.RegisterFlagCompletionFunc(STATUS, completeStatuses)
+
+	editCommand.Flags().Var(&completeFlag, COMPLETE, "Mark task complete or not. The same as --status done or back to todo")
+
+	editCommand.MarkFlagsMutuallyExclusive(STATUS, COMPLETE)
+
+	editCommand.Flags().Var(&repeatFlag, REPEAT, "Repeat the task like daily, weekly on mon/wed, monthly on the 1st or none to stop repeating")
+	editCommand.Flags().Var(&dueFlag, DUE, "Set when the task is due like tomorrow 17:00, next fri, in 3 days, YYYY-MM-DD or none")
+
+	editCommand.Flags().StringArray(ADD_TAG, nil, "Add a tag to the task. Pass it more than once for more tags")
+	editCommand.Flags().StringArray(REMOVE_TAG, nil, "Remove a tag from the task. Pass it more than once for more tags")
+
+	editCommand.RegisterFlagCompletionFunc(ADD_TAG, completeTags)
+	editCommand.RegisterFlagCompletionFunc(REMOVE_TAG, completeTags)
+
+	editCommand.Flags().StringArray(BLOCKED_BY, nil, "Make the task wait for the task with this id. Pass it more than once for more tasks")
+	editCommand.Flags().StringArray(UNBLOCK, nil, "Stop the task waiting for the task with this id. Pass it more than once for more tasks")
+
+	editCommand.Flags().Bool(FORCE, false, "Overwrite changes someone else saved while you were editing")
+	editCommand.Flags().Bool(MERGE, false, "Merge changes someone else saved while you were editing")
+
+	editCommand.MarkFlagsMutuallyExclusive(FORCE, MERGE)
+
+	editCommand.Flags().String(WHERE, "", whereFlagUsage("Edit"))
+
+	printsTasks(editCommand)
 
 	return editCommand
 }
 
+// resolveConflict decides what to save when the task changed after it was read.
+// force keeps yours as is.
+// merge keeps every change that doesn't clash and asks which side wins for the rest.
+func resolveConflict(conflictError task.ConflictError, base, yours, theirs task.Task, force, merge bool) (task.Task, error) {
+
+	conflictError.Yours = &yours
+
+	if force {
+		return yours.Rebase(theirs), nil
+	}
+
+	if !merge {
+		return yours, fmt.Errorf(
+			"%w\nPass --%s to overwrite their changes or --%s to merge them",
+			conflictError,
+			FORCE,
+			MERGE,
+		)
+	}
+
+	merged, conflicts := task.Merge(base, yours, theirs)
+
+	if len(conflicts) == 0 {
+		return merged, nil
+	}
+
+	choices := make([]string, len(conflicts))
+
+	fields := lo.Map(conflicts, func(field string, index int) huh.Field {
+		return huh.NewSelect[string]().
+			Title(fmt.Sprintf("Both of you changed the %s", field)).
+			Value(&choices[index]).
+			Options(
+				huh.NewOption(fmt.Sprintf("Yours: %s", yours.FieldValue(field)), "yours"),
+				huh.NewOption(fmt.Sprintf("Theirs: %s", theirs.FieldValue(field)), "theirs"),
+			)
+	})
+
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return yours, err
+	}
+
+	lo.ForEach(conflicts, func(field string, index int) {
+		if choices[index] == "yours" {
+			merged.TakeField(field, yours)
+		}
+	})
+
+	return merged, nil
+}
+
+// taskEdits is what the flags or the form say to change in a task.
+type taskEdits struct {
+	title, description, priority, complete, status, due, repeat string
+	repeatValue                                                 *task.Recurrence
+	addTags, removeTags, blockedBy, unblock                     []string
+}
+
+// apply makes the edits to a task and says whether anything changed.
+func (self taskEdits) apply(cmd *cobra.Command, store task.TaskStore, edited task.Task) (task.Task, bool, error) {
+
+	changed := false
+
+	if self.title != "" && edited.Title != self.title {
+		edited.Title = self.title
+		changed = true
+	}
+
+	if self.description != "" && edited.Description != self.description {
+		edited.Description = self.description
+		changed = true
+	}
+
+	if self.priority != "" {
+		parsedPriority, err := task.ParsePriority(self.priority)
+
+		if err != nil {
+			return edited, false, err
+		}
+
+		if parsedPriority.Value() != edited.Priority.Value() {
+			edited.Priority = parsedPriority
+			changed = true
+		}
+	}
+
+	if self.status != "" {
+
+		workflow, err := taskWorkflow()
+
+		if err != nil {
+			return edited, false, err
+		}
+
+		parsedStatus, err := workflow.Parse(self.status)
+
+		if err != nil {
+			return edited, false, err
+		}
+
+		if parsedStatus != edited.Status {
+			edited.Status = parsedStatus
+			changed = true
+		}
+	}
+
+	if self.complete != "" {
+
+		parsedComplete, err := strconv.ParseBool(self.complete)
+
+		if err != nil {
+			return edited, false, err
+		}
+
+		previousStatus := edited.Status
+
+		edited = edited.MarkComplete(parsedComplete)
+
+		if edited.Status != previousStatus {
+			changed = true
+		}
+	}
+
+	if self.due != "" {
+
+		parsedDue, err := parseDueDate(self.due)
+
+		if err != nil {
+			return edited, false, err
+		}
+
+		previousDue := edited.DueTimeStamp()
+
+		edited.Due = parsedDue
+
+		if edited.DueTimeStamp() != previousDue {
+			changed = true
+		}
+	}
+
+	if len(self.addTags) > 0 || len(self.removeTags) > 0 {
+
+		parsedAddTags, addTagsErr := task.ParseTags(self.addTags)
+		parsedRemoveTags, removeTagsErr := task.ParseTags(self.removeTags)
+
+		if err := errors.Join(addTagsErr, removeTagsErr); err != nil {
+			return edited, false, err
+		}
+
+		previousTags := edited.Tags
+
+		edited.Tags = edited.AddTags(parsedAddTags...)
+		edited.Tags = edited.RemoveTags(parsedRemoveTags...)
+
+		if !slices.Equal(previousTags, edited.Tags) {
+			changed = true
+		}
+	}
+
+	if self.repeat != "" {
+
+		previousRepeat := edited.FieldValue("repeat")
+
+		edited.Repeat = self.repeatValue
+
+		if edited.FieldValue("repeat") != previousRepeat {
+			changed = true
+		}
+	}
+
+	if len(self.blockedBy) > 0 || len(self.unblock) > 0 {
+
+		blockedBy := slices.Clone(self.blockedBy)
+		unblock := slices.Clone(self.unblock)
+
+		for index, reference := range blockedBy {
+
+			blocker, err := resolveTask(cmd, store, reference)
+
+			if err != nil {
+				return edited, false, err
+			}
+
+			if blocker.Id() == edited.Id() {
+				return edited, false, fmt.Errorf("%w A task can't block itself %s", custom_errors.InvalidArgument, edited.Id())
+			}
+
+			blockedBy[index] = blocker.Id()
+		}
+
+		// A blocker that was deleted can't be resolved anymore so its id is used as is.
+		for index, reference := range unblock {
+
+			if blocker, err := resolveTask(cmd, store, reference); err == nil {
+				unblock[index] = blocker.Id()
+			}
+		}
+
+		previousBlockedBy := edited.BlockedBy
+
+		edited.BlockedBy = edited.AddBlockers(blockedBy...)
+		edited.BlockedBy = edited.RemoveBlockers(unblock...)
+
+		if !slices.Equal(previousBlockedBy, edited.BlockedBy) {
+			changed = true
+		}
+	}
+	return edited, changed, nil
+}
+
+// saveEditedTask completes subtasks, creates the next instance of a repeating task and saves it.
+// Everything is saved at once so a failure can't complete a repeating task without its next instance.
+// It returns the saved task and the next instance when one was created.
+func saveEditedTask(store task.TaskStore, edited, base task.Task) (task.Task, *task.Task, error) {
+
+	completedSubtasks, err := completeSubtasks(store, edited, base)
+
+	if err != nil {
+		return edited, nil, err
+	}
+
+	nextInstance, repeats := edited.NextInstance(time.Now())
+
+	repeats = repeats && edited.Done() && !base.Done()
+
+	if repeats {
+		edited.Repeat = nil
+	}
+
+	puts := append(completedSubtasks, edited)
+
+	if repeats {
+		puts = append(puts, nextInstance)
+	}
+
+	if err := task.SaveChanges(store, puts, nil); err != nil {
+		return edited, nil, err
+	}
+
+	if !repeats {
+		return edited, nil, nil
+	}
+
+	return edited, &nextInstance, nil
+}
+
+// editWhere makes the same edits to every task in the list the query matches.
+// The tasks are read while the store is locked so nobody can change them in between.
+func editWhere(cmd *cobra.Command, store task.TaskStore, where query.Query, edits taskEdits, out output) error {
+
+	list, err := activeList(cmd)
+
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockStore(cmd, store)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	tasks, err := store.Load()
+
+	if err != nil {
+		return err
+	}
+
+	matches := where.Filter(task.InList(tasks, list), query.Env{Now: time.Now(), Tasks: tasks})
+
+	if len(matches) == 0 {
+		return custom_errors.CreateInvalidArgumentErrorWithMessage(
+			fmt.Sprintf("There are no tasks that match %s", where.Input()),
+		)
+	}
+
+	editedTasks := []task.Task{}
+
+	for _, match := range matches {
+
+		// An earlier edit may have completed this task as a subtask so the stored one is used.
+		base, err := store.Get(match.Id())
+
+		if err != nil {
+			return err
+		}
+
+		edited, changed, err := edits.apply(cmd, store, base)
+
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			continue
+		}
+
+		edited, _, err = saveEditedTask(store, edited.Revise(), base)
+
+		if err != nil {
+			return err
+		}
+
+		editedTasks = append(editedTasks, edited)
+	}
+
+	if out.passed() {
+
+		tasks, err := store.Load()
+
+		if err != nil {
+			return err
+		}
+
+		return out.print(cmd.OutOrStdout(), printedTasks{tasks: editedTasks, all: tasks}, false)
+	}
+
+	plain, err := cmd.Flags().GetBool(PLAIN)
+
+	if err != nil {
+		return err
+	}
+
+	if plain {
+
+		tasksAsJSON, err := task.MarshallTasks(editedTasks)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), tasksAsJSON)
+
+		return nil
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%d of the %d matching tasks were changed\n", len(editedTasks), len(matches))
+
+	return nil
+}
+
+// completeSubtasks applies the parent completion rule when a task is being completed.
+func completeSubtasks(store task.TaskStore, edited, base task.Task) ([]task.Task, error) {
+
+	if !edited.Done() || base.Done() {
+		return nil, nil
+	}
+
+	rule, err := parentCompletionRule()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := store.Load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return task.CompleteParent(tasks, edited.Id(), rule)
+}
+
 func init() {
 	rootCmd.AddCommand(CreateEditCmd())
 }
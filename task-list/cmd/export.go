@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/task"
+	"github.com/spf13/cobra"
+)
+
+const FORMAT = "format"
+const FILE = "file"
+const COLUMNS = "columns"
+
+// FORMATS are the file formats tasks can be imported from and exported to.
+var FORMATS = []string{task.TODOTXT, task.CSV, task.MARKDOWN, task.ICS}
+
+// CreateExportCommand represents the export command
+func CreateExportCommand() *cobra.Command {
+
+	formatFlag := flags.NewUnionFlag(FORMATS, FORMAT)
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write your tasks in a format other apps understand",
+		Long: `Writes the tasks in the active list to standard output or to --file
+in the format passed to --format, oldest first.
+todotxt writes a todo.txt line for every task with tags as +projects and @contexts
+and the due date and metadata as key:value extras.
+csv writes a header and a row for every task with the columns passed to --columns
+or every field when there are none.
+markdown writes a - [ ] checklist with a heading for each priority and descriptions indented under their task.
+ics writes an iCalendar file with a VTODO for every task that calendar apps can show.
+Pass --all-lists to export every list.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			allLists, error := cmd.Flags().GetBool(ALL_LISTS)
+
+			if error != nil {
+				return error
+			}
+
+			path, error := cmd.Flags().GetString(FILE)
+
+			if error != nil {
+				return error
+			}
+
+			columns, error := cmd.Flags().GetStringSlice(COLUMNS)
+
+			if error != nil {
+				return error
+			}
+
+			if cmd.Flags().Changed(COLUMNS) && formatFlag.String() != task.CSV {
+				return custom_errors.CreateInvalidFlagErrorWithMessage(COLUMNS, "only works with --format csv")
+			}
+
+			columns, error = task.ParseCSVColumns(columns)
+
+			if error != nil {
+				return fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, COLUMNS, error)
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			if !allLists {
+
+				list, error := activeList(cmd)
+
+				if error != nil {
+					return error
+				}
+
+				tasks = task.InList(tasks, list)
+			}
+
+			// Tasks are stored newest first.
+			slices.Reverse(tasks)
+
+			var writer io.Writer = cmd.OutOrStdout()
+
+			if path != "" {
+
+				file, error := os.Create(path)
+
+				if error != nil {
+					return error
+				}
+
+				defer file.Close()
+
+				writer = file
+			}
+
+			switch formatFlag.String() {
+			case task.TODOTXT:
+				error = task.WriteTodoTxt(writer, tasks)
+			case task.CSV:
+				error = task.WriteCSV(writer, tasks, columns)
+			case task.MARKDOWN:
+				error = task.WriteMarkdown(writer, tasks)
+			case task.ICS:
+				error = task.WriteICS(writer, tasks)
+			}
+
+			if error != nil {
+				return error
+			}
+
+			if path != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Exported %d tasks to %s\n", len(tasks), path)
+			}
+
+			return nil
+		},
+	}
+
+	exportCmd.Flags().Var(&formatFlag, FORMAT, fmt.Sprintf("The format to write one of %v", FORMATS))
+	exportCmd.Flags().StringP(FILE, "f", "", "Write to this file instead of standard output")
+	exportCmd.Flags().StringSlice(COLUMNS, task.CSVColumns(), "The columns to write with --format csv in order")
+	exportCmd.Flags().Bool(ALL_LISTS, false, "Export the tasks in every list")
+	exportCmd.MarkFlagRequired(FORMAT)
+
+	return exportCmd
+}
+
+func init() {
+	rootCmd.AddCommand(CreateExportCommand())
+}
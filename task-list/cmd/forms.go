@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mini-clis/task-list/dates"
+	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+)
+
+// parseDueDate turns what was typed into a due date form field into a due date.
+// An empty value or none means the task has no due date.
+func parseDueDate(value string) (*time.Time, error) {
+
+	if value == "" || value == flags.NO_DATE {
+		return nil, nil
+	}
+
+	parsedTime, error := dates.Parse(value)
+
+	if error != nil {
+		return nil, error
+	}
+
+	return &parsedTime, nil
+}
+
+func dueDateInput(value *string) *huh.Input {
+
+	return huh.NewInput().
+		Title("Due").
+		Description("When does this task need to be done? Leave it empty if it doesn't matter").
+		Placeholder("tomorrow 17:00, next fri, in 3 days or YYYY-MM-DD").
+		Value(value).
+		Validate(func(value string) error {
+			_, error := parseDueDate(value)
+			return error
+		})
+}
+
+// parseTagList splits what was typed into a tags form field on commas.
+func parseTagList(value string) ([]string, error) {
+
+	inputs := lo.Filter(strings.Split(value, ","), func(item string, index int) bool {
+		return strings.TrimSpace(item) != ""
+	})
+
+	return task.ParseTags(inputs)
+}
+
+func tagsInput(value *string) *huh.Input {
+
+	return huh.NewInput().
+		Title("Tags").
+		Description("Separate tags with commas").
+		Placeholder("work, errands").
+		Value(value).
+		Validate(func(value string) error {
+			_, error := parseTagList(value)
+			return error
+		})
+}
+
+// statusInput picks a status from the workflow.
+// A status that was removed from the config is still offered so the task can keep it.
+func statusInput(value *string, workflow task.Workflow) *huh.Select[string] {
+
+	statuses := lo.Uniq(append(workflow.Values(), *value))
+
+	return huh.NewSelect[string]().
+		Title("Status").
+		Description("Where is this task in the workflow?").
+		Value(value).
+		Options(lo.Map(statuses, func(status string, index int) huh.Option[string] {
+			return huh.NewOption(lo.Capitalize(status), status)
+		})...)
+}
+
+func formatDueDate(due *time.Time) string {
+
+	if due == nil {
+		return ""
+	}
+
+	if due.Hour() == 0 && due.Minute() == 0 {
+		return due.Format(time.DateOnly)
+	}
+
+	return due.Format("2006-01-02 15:04")
+}
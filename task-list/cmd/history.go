@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/task"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// CreateHistoryCommand represents the history command
+func CreateHistoryCommand() *cobra.Command {
+
+	historyCmd := &cobra.Command{
+		Use:   "history <id>",
+		Short: "See how a task changed over time",
+		Long: `Shows every command that changed a task oldest first
+with the old and new value of each field it changed.
+Deleted tasks still have a history so you can see what they were before undoing.
+`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTaskIds,
+		SilenceUsage:      true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			store, journal, error := storeAndJournal(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			found, error := resolveHistoryTask(cmd, store, journal, args[0])
+
+			if error != nil {
+				return error
+			}
+
+			entries, error := journal.History(found.Id())
+
+			if error != nil {
+				return error
+			}
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			if plain {
+
+				historyAsJSON, error := task.MarshallHistory(entries)
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), historyAsJSON)
+
+				return nil
+			}
+
+			if len(entries) == 0 {
+
+				fmt.Fprintf(cmd.OutOrStdout(), "There are no changes recorded for %s", found.Title)
+
+				return nil
+			}
+
+			for _, entry := range entries {
+
+				fmt.Fprintf(
+					cmd.OutOrStdout(),
+					"%s %s %s\n",
+					pterm.FgGray.Sprint(entry.Time.Format("2006-01-02 15:04:05")),
+					entry.Command,
+					pterm.FgGray.Sprintf("(%s)", entry.Kind),
+				)
+
+				for _, change := range entry.Changes {
+
+					if change.Old != "" {
+						fmt.Fprintln(cmd.OutOrStdout(), pterm.FgRed.Sprintf("  - %s: %s", change.Field, change.Old))
+					}
+
+					if change.New != "" {
+						fmt.Fprintln(cmd.OutOrStdout(), pterm.FgGreen.Sprintf("  + %s: %s", change.Field, change.New))
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return historyCmd
+}
+
+// resolveHistoryTask finds the task like every other command
+// falling back to the tasks in the journal so deleted tasks can be found too.
+func resolveHistoryTask(cmd *cobra.Command, store task.TaskStore, journal task.Journal, reference string) (task.Task, error) {
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return task.Task{}, error
+	}
+
+	journaledTasks, error := journal.Tasks()
+
+	if error != nil {
+		return task.Task{}, error
+	}
+
+	deletedTasks := lo.Reject(journaledTasks, func(item task.Task, index int) bool {
+		return lo.ContainsBy(tasks, func(stored task.Task) bool { return stored.Id() == item.Id() })
+	})
+
+	list, error := activeList(cmd)
+
+	if error != nil {
+		return task.Task{}, error
+	}
+
+	found, error := task.Resolve(append(tasks, deletedTasks...), reference, list)
+
+	if errors.Is(error, task.ErrTaskNotFound) {
+		return found, fmt.Errorf(
+			"%w Task with this id wasn't found %s",
+			custom_errors.InvalidArgument,
+			reference,
+		)
+	}
+
+	return found, error
+}
+
+func init() {
+	rootCmd.AddCommand(CreateHistoryCommand())
+}
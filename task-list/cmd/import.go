@@ -0,0 +1,189 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+const MAP = "map"
+
+// CreateImportCommand represents the import command
+func CreateImportCommand() *cobra.Command {
+
+	formatFlag := flags.NewUnionFlag(FORMATS, FORMAT)
+
+	importCmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Add the tasks from a file another app wrote",
+		Long: `Adds every task in a file written in the format passed to --format to the active list.
+It reads standard input when there's no file or the file is -.
+todotxt reads (A), (B) and (C) as high, medium and low priority, x as complete
+and the creation date as when the task was created.
++projects and @contexts become tags, due:YYYY-MM-DD sets the due date
+and other key:value extras are kept as metadata so exporting gives back the same file.
+csv reads a task from every row matching headers to fields by name.
+Pass --map "Header=field" as many times as you like for headers with other names.
+Rows with a cell that isn't valid are skipped and listed with why once the other rows are imported.
+markdown reads every - [ ] and - [x] item like the ones in a PR description.
+Headings like ## High set the priority and items indented under another become its subtasks.
+ics reads every VTODO in an iCalendar file with SUMMARY as the title, PRIORITY 1 to 4 as high,
+5 as medium and the rest as low, and a COMPLETED status or date as complete.
+`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			list, error := activeList(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			mapFlagValues, error := cmd.Flags().GetStringArray(MAP)
+
+			if error != nil {
+				return error
+			}
+
+			if len(mapFlagValues) > 0 && formatFlag.String() != task.CSV {
+				return custom_errors.CreateInvalidFlagErrorWithMessage(MAP, "only works with --format csv")
+			}
+
+			mapping, error := task.ParseCSVMapping(mapFlagValues)
+
+			if error != nil {
+				return fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, MAP, error)
+			}
+
+			var reader io.Reader = cmd.InOrStdin()
+
+			if len(args) == 1 && args[0] != "-" {
+
+				file, error := os.Open(args[0])
+
+				if error != nil {
+					return error
+				}
+
+				defer file.Close()
+
+				reader = file
+			}
+
+			var imported []task.Task
+			var rowErrors []task.RowError
+
+			switch formatFlag.String() {
+			case task.TODOTXT:
+				imported, error = task.ReadTodoTxt(reader, list)
+			case task.CSV:
+				imported, rowErrors, error = task.ReadCSV(reader, list, mapping)
+			case task.MARKDOWN:
+				imported, error = task.ReadMarkdown(reader, list)
+			case task.ICS:
+				imported, error = task.ReadICS(reader, list)
+			}
+
+			if error != nil {
+				return error
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			// Tasks are stored newest first so the first line ends up last.
+			added := slices.Clone(imported)
+			slices.Reverse(added)
+
+			if error := store.Save(append(added, tasks...)); error != nil {
+				return error
+			}
+
+			savedTasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			// The saved tasks have their numbers.
+			ids := lo.Map(imported, func(item task.Task, index int) string { return item.Id() })
+
+			if error := printImportedTasks(cmd, lo.Filter(savedTasks, func(item task.Task, index int) bool {
+				return lo.Contains(ids, item.Id())
+			})); error != nil {
+				return error
+			}
+
+			if len(rowErrors) == 0 {
+				return nil
+			}
+
+			return task.InvalidRowsError{Imported: len(imported), Errors: rowErrors}
+		},
+	}
+
+	importCmd.Flags().Var(&formatFlag, FORMAT, fmt.Sprintf("The format to read one of %v", FORMATS))
+	importCmd.Flags().StringArray(MAP, []string{}, "Read a CSV header into a field like --map \"Summary=title\"")
+	importCmd.MarkFlagRequired(FORMAT)
+
+	return importCmd
+}
+
+// printImportedTasks shows what import added.
+func printImportedTasks(cmd *cobra.Command, imported []task.Task) error {
+
+	plain, error := cmd.Flags().GetBool(PLAIN)
+
+	if error != nil {
+		return error
+	}
+
+	if plain {
+
+		tasksAsJSON, error := task.MarshallTasks(imported)
+
+		if error != nil {
+			return error
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), tasksAsJSON)
+
+		return nil
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Imported %d tasks\n", len(imported))
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(CreateImportCommand())
+}
@@ -8,9 +8,13 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/mini-clis/shared/custom_errors"
 	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/query"
 	"github.com/mini-clis/task-list/task"
+	"github.com/pterm/pterm"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/tidwall/pretty"
@@ -20,9 +24,11 @@ const LATEST = "latest"
 
 const EARLIEST = "earliest"
 
+// DUE as a sort date puts the soonest due tasks first and tasks without a due date last.
 var allowedDateSortValues = []string{
 	LATEST,
 	EARLIEST,
+	DUE,
 }
 
 const HIGHEST = "highest"
@@ -39,6 +45,14 @@ const FILTER_COMPLETE = "filter-complete"
 const FILTER_INCOMPLETE = "filter-incomplete"
 const SORT_DATE = "sort-date"
 const SORT_PRIORITY = "sort-priority"
+const OVERDUE = "overdue"
+const DUE_BEFORE = "due-before"
+const DUE_AFTER = "due-after"
+const NOT_TAG = "not-tag"
+const TAG_MATCH = "tag-match"
+const TREE = "tree"
+const RECURRING = "recurring"
+const ALL_LISTS = "all-lists"
 
 // listCmd represents the list command
 func CreateListCommand() *cobra.Command {
@@ -46,12 +60,23 @@ func CreateListCommand() *cobra.Command {
 	filterPriorityFlag := flags.NewUnionFlag(task.AllowedProrities, FILTER_PRIORITY)
 	sortPriorityFlag := flags.NewUnionFlag(allowedPrioritySortValues, SORT_PRIORITY)
 	sortDateFlag := flags.NewUnionFlag(allowedDateSortValues, SORT_DATE)
+	dueBeforeFlag := flags.NewDateFlag(DUE_BEFORE)
+	dueAfterFlag := flags.NewDateFlag(DUE_AFTER)
+	tagMatchFlag := flags.NewUnionFlag(task.AllowedTagMatches, TAG_MATCH)
 
 	var listCmd = &cobra.Command{
 		Use:   "list",
 		Short: "Get a list of all of your tasks",
 		Long: `Get a list of all the tasks that you need to do today.
 			You will see the
+			Pass --tree to see subtasks indented under their parents with how many of them are done.
+			Pass --recurring to see the tasks that repeat along with their rules.
+			Only tasks in the active list are shown unless you pass --all-lists which groups them by list.
+			Tasks are shown in a table that fits the terminal with the columns passed to --columns.
+			Pass --plain or --output json to get JSON instead or --output with ndjson, yaml, csv or template
+			to get every matching task in that format.
+			Pass --where with a query like 'priority >= medium and not complete and (title ~ "deploy" or created > -7d)'
+			to combine filters with and, or, not and brackets.
 			`,
 		Args:         cobra.NoArgs,
 		SilenceUsage: true,
@@ -61,21 +86,61 @@ func CreateListCommand() *cobra.Command {
 
 			filterIncomplete, filterIncompleteErr := cmd.Flags().GetBool(FILTER_INCOMPLETE)
 
+			overdue, overdueErr := cmd.Flags().GetBool(OVERDUE)
+
+			tags, tagsErr := cmd.Flags().GetStringArray(TAG)
+
+			notTags, notTagsErr := cmd.Flags().GetStringArray(NOT_TAG)
+
 			flagError := errors.Join(
 				filterCompleteError,
 				filterIncompleteErr,
+				overdueErr,
+				tagsErr,
+				notTagsErr,
 			)
 
 			if flagError != nil {
 				return flagError
 			}
 
-			tasks, tasksErr := task.ReadTasks()
+			where, hasWhere, whereErr := whereQuery(cmd)
+
+			if whereErr != nil {
+				return whereErr
+			}
+
+			store, storeErr := taskStore(cmd)
+
+			if storeErr != nil {
+				return storeErr
+			}
+
+			tasks, tasksErr := store.Load()
 
 			if tasksErr != nil {
 				return tasksErr
 			}
 
+			allTasks := slices.Clone(tasks)
+
+			allLists, allListsErr := cmd.Flags().GetBool(ALL_LISTS)
+
+			if allListsErr != nil {
+				return allListsErr
+			}
+
+			if !allLists {
+
+				list, listErr := activeList(cmd)
+
+				if listErr != nil {
+					return listErr
+				}
+
+				tasks = task.InList(tasks, list)
+			}
+
 			if sortPriorityFlag.String() == HIGHEST {
 				slices.SortFunc(tasks, func(a task.Task, b task.Task) int {
 					return b.Priority.Order() - a.Priority.Order()
@@ -112,15 +177,63 @@ func CreateListCommand() *cobra.Command {
 				})
 			}
 
+			if sortDateFlag.String() == DUE {
+				slices.SortStableFunc(tasks, func(a task.Task, b task.Task) int {
+					switch {
+					case a.Due == nil && b.Due == nil:
+						return 0
+					case a.Due == nil:
+						return 1 // tasks without a due date go last
+					case b.Due == nil:
+						return -1
+					}
+					return a.Due.Compare(*b.Due)
+				})
+			}
+
+			now := time.Now()
+
+			if overdue {
+				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
+					return item.Overdue(now)
+				})
+			}
+
+			if dueBefore := dueBeforeFlag.Value(); dueBefore != nil {
+				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
+					return item.Due != nil && item.Due.Before(*dueBefore)
+				})
+			}
+
+			if dueAfter := dueAfterFlag.Value(); dueAfter != nil {
+				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
+					return item.Due != nil && item.Due.After(*dueAfter)
+				})
+			}
+
+			tagMatch := lo.CoalesceOrEmpty(tagMatchFlag.String(), task.ANY)
+
+			if len(tags) > 0 {
+				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
+					return item.MatchesTags(tags, tagMatch)
+				})
+			}
+
+			if len(notTags) > 0 {
+				tasks = lo.Reject(tasks, func(item task.Task, index int) bool {
+					return item.MatchesTags(notTags, tagMatch)
+				})
+			}
+
 			if filterComplete {
 				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
-					return item.Complete
+					return item.Done()
 				})
 			}
 
 			if filterIncomplete {
 				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
-					return !item.Complete
+					return !item.Done()
 				})
 			}
 
@@ -130,26 +243,135 @@ func CreateListCommand() *cobra.Command {
 				})
 			}
 
-			if len(tasks) == 0 {
+			if hasWhere {
+				tasks = where.Filter(tasks, query.Env{Now: now, Tasks: allTasks})
+			}
+
+			recurring, recurringErr := cmd.Flags().GetBool(RECURRING)
+
+			if recurringErr != nil {
+				return recurringErr
+			}
+
+			if recurring {
+				tasks = lo.Filter(tasks, func(item task.Task, index int) bool {
+					return item.Repeat != nil
+				})
+			}
+
+			out, outErr := commandOutput(cmd)
+
+			if outErr != nil {
+				return outErr
+			}
 
-				fmt.Printf("There are no tasks with this priority %s", filterPriorityFlag.String())
+			if len(tasks) == 0 && !out.passed() {
+
+				fmt.Fprint(cmd.OutOrStdout(), "There are no tasks that match these filters")
 
 				return nil
 			}
 
-			stringifiedTasks, stringifiedTasksErr := task.MarshallTasks(tasks)
+			format, formatErr := listFormat(cmd, out)
 
-			if stringifiedTasksErr != nil {
-				return stringifiedTasksErr
+			if formatErr != nil {
+				return formatErr
 			}
 
-			plain, error := cmd.Flags().GetBool(PLAIN)
+			plain := format == JSON
+
+			columnNames, columnNamesErr := cmd.Flags().GetStringSlice(COLUMNS)
+
+			if columnNamesErr != nil {
+				return columnNamesErr
+			}
 
-			if error != nil {
-				return error
+			if cmd.Flags().Changed(COLUMNS) && format != TABLE {
+				return custom_errors.CreateInvalidFlagErrorWithMessage(COLUMNS, "only works with the table output")
+			}
+
+			columns, columnsErr := parseTableColumns(columnNames)
+
+			if columnsErr != nil {
+				return fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, COLUMNS, columnsErr)
+			}
+
+			// Only json and the table have their own shape for trees and groups.
+			if format != JSON && format != TABLE {
+				return out.print(cmd.OutOrStdout(), printedTasks{tasks: tasks, all: allTasks}, false)
+			}
+
+			width := terminalWidth(cmd.OutOrStdout())
+
+			tree, treeErr := cmd.Flags().GetBool(TREE)
+
+			if treeErr != nil {
+				return treeErr
+			}
+
+			if tree && plain {
+
+				stringifiedTree, stringifiedTreeErr := task.MarshallTree(task.BuildTree(tasks))
+
+				if stringifiedTreeErr != nil {
+					return stringifiedTreeErr
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), stringifiedTree)
+
+				return nil
+			}
+
+			if tree {
+
+				fmt.Fprint(cmd.OutOrStdout(), renderTaskTree(task.BuildTree(tasks), allTasks, now))
+
+				return nil
+			}
+
+			if allLists {
+
+				registry, registryErr := listRegistry(cmd)
+
+				if registryErr != nil {
+					return registryErr
+				}
+
+				groups := task.GroupByList(tasks, registry.Names())
+
+				if plain {
+
+					stringifiedGroups, stringifiedGroupsErr := task.NewListing(allTasks).MarshallGroups(groups)
+
+					if stringifiedGroupsErr != nil {
+						return stringifiedGroupsErr
+					}
+
+					fmt.Fprintln(cmd.OutOrStdout(), stringifiedGroups)
+
+					return nil
+				}
+
+				fmt.Fprint(cmd.OutOrStdout(), renderListGroups(groups, allTasks, now, columns, width))
+
+				return nil
+			}
+
+			if recurring && !plain {
+
+				fmt.Fprint(cmd.OutOrStdout(), renderRecurringTasks(tasks))
+
+				return nil
 			}
 
 			if plain {
+
+				stringifiedTasks, stringifiedTasksErr := task.NewListing(allTasks).Marshall(tasks)
+
+				if stringifiedTasksErr != nil {
+					return stringifiedTasksErr
+				}
+
 				fmt.Fprintln(
 					cmd.OutOrStdout(),
 					stringifiedTasks,
@@ -158,15 +380,8 @@ func CreateListCommand() *cobra.Command {
 				return nil
 			}
 
-			fmt.Fprint(
-				cmd.OutOrStdout(),
-				string(
-					pretty.Color(
-						pretty.Pretty([]byte(stringifiedTasks)),
-						nil,
-					),
-				),
-			)
+			fmt.Fprint(cmd.OutOrStdout(), renderTaskTable(tasks, allTasks, now, columns, width))
+
 			return nil
 
 		},
@@ -222,12 +437,168 @@ func CreateListCommand() *cobra.Command {
 			return allowedPrioritySortValues, cobra.ShellCompDirectiveDefault
 		},
 	)
-	listCmd.MarkFlagsMutuallyExclusive(SORT_PRIORITY, FILTER_PRIORITY)
+
+	listCmd.Flags().Bool(OVERDUE, false, "Filter tasks that are past their due date and incomplete")
+	listCmd.Flags().Var(&dueBeforeFlag, DUE_BEFORE, "Filter tasks due before a date")
+	listCmd.Flags().Var(&dueAfterFlag, DUE_AFTER, "Filter tasks due after a date")
+
+	listCmd.Flags().StringArray(TAG, nil, "Filter tasks with a tag. Pass it more than once for more tags")
+	listCmd.Flags().StringArray(NOT_TAG, nil, "Filter out tasks with a tag. Pass it more than once for more tags")
+	listCmd.Flags().Var(
+		&tagMatchFlag,
+		TAG_MATCH,
+		fmt.Sprintf("Whether tasks need %s of the tags or %s of them", task.ALL, task.ANY),
+	)
+
+	listCmd.RegisterFlagCompletionFunc(TAG, completeTags)
+	listCmd.RegisterFlagCompletionFunc(NOT_TAG, completeTags)
+	listCmd.RegisterFlagCompletionFunc(
+		TAG_MATCH,
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+			return task.AllowedTagMatches, cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	listCmd.Flags().String(WHERE, "", whereFlagUsage("Filter"))
+
+	listCmd.Flags().Bool(TREE, false, "Show subtasks indented under their parents")
+	listCmd.Flags().Bool(RECURRING, false, "Show the tasks that repeat and their rules")
+
+	listCmd.Flags().Bool(ALL_LISTS, false, "Show the tasks in every list grouped by list")
+
+	listCmd.MarkFlagsMutuallyExclusive(TREE, RECURRING, ALL_LISTS)
+
+	listCmd.Flags().StringSlice(
+		COLUMNS,
+		defaultTableColumns,
+		fmt.Sprintf("The columns to show in the table in order from %s", strings.Join(tableColumnNames(), ",")),
+	)
+
+	listCmd.MarkFlagsMutuallyExclusive(COLUMNS, TREE)
+	listCmd.MarkFlagsMutuallyExclusive(COLUMNS, RECURRING)
+
+	printsTasks(listCmd)
 
 	return listCmd
 
 }
 
+// overdueStyle paints the keys of overdue tasks red so they stand out.
+var overdueStyle = func() *pretty.Style {
+
+	style := *pretty.TerminalStyle
+
+	style.Key = [2]string{"\x1b[1;31m", "\x1b[0m"}
+
+	return &style
+}()
+
+// renderTaskTree draws one line per task with subtasks indented under their parent.
+// Parents show how many of their subtasks are done and blocked tasks name their blockers.
+func renderTaskTree(nodes []task.TreeNode, all []task.Task, now time.Time) string {
+
+	var builder strings.Builder
+
+	listing := task.NewListing(all)
+
+	var render func(nodes []task.TreeNode, depth int)
+
+	render = func(nodes []task.TreeNode, depth int) {
+
+		for _, node := range nodes {
+
+			line := fmt.Sprintf(
+				"%s[%s] %s",
+				strings.Repeat("  ", depth),
+				lo.Ternary(node.Task.Done(), "x", " "),
+				node.Task.Title,
+			)
+
+			if done, total := node.Counts(); total > 0 {
+				line += fmt.Sprintf(" (%d/%d)", done, total)
+			}
+
+			line += fmt.Sprintf(" %s", listing.ShortId(node.Task))
+
+			if blockers := node.Task.Blockers(all); len(blockers) > 0 {
+				line += fmt.Sprintf(
+					" blocked by %s",
+					strings.Join(lo.Map(blockers, func(item task.Task, index int) string { return item.Title }), ", "),
+				)
+			}
+
+			if node.Task.Overdue(now) {
+				line = overdueStyle.Key[0] + line + overdueStyle.Key[1]
+			}
+
+			builder.WriteString(line + "\n")
+
+			render(node.Children, depth+1)
+		}
+	}
+
+	render(nodes, 0)
+
+	return builder.String()
+}
+
+// renderListGroups puts a heading above the table of tasks in each list.
+func renderListGroups(groups []task.ListGroup, all []task.Task, now time.Time, columns []tableColumn, width int) string {
+
+	var builder strings.Builder
+
+	for _, group := range groups {
+
+		builder.WriteString(pterm.FgCyan.Sprintf("%s (%d)\n", group.List, len(group.Tasks)))
+
+		if len(group.Tasks) == 0 {
+			continue
+		}
+
+		builder.WriteString(renderTaskTable(group.Tasks, all, now, columns, width))
+	}
+
+	return builder.String()
+}
+
+// listFormat is the format passed to --output.
+// Without it --plain means json and the table is shown otherwise.
+func listFormat(cmd *cobra.Command, out output) (string, error) {
+
+	if out.passed() {
+		return out.format, nil
+	}
+
+	plain, error := cmd.Flags().GetBool(PLAIN)
+
+	if error != nil {
+		return "", error
+	}
+
+	return lo.Ternary(plain, JSON, TABLE), nil
+}
+
+// renderRecurringTasks shows one line per rule with when the current instance is due.
+func renderRecurringTasks(tasks []task.Task) string {
+
+	var builder strings.Builder
+
+	for _, item := range tasks {
+
+		fmt.Fprintf(
+			&builder,
+			"%s %s %s %s\n",
+			pterm.FgCyan.Sprint(item.Title),
+			item.Repeat.Describe(),
+			pterm.FgGray.Sprintf("(%s)", item.Repeat),
+			lo.Ternary(item.Due == nil, "not due", "due "+formatDueDate(item.Due)),
+		)
+	}
+
+	return builder.String()
+}
+
 func init() {
 
 	rootCmd.AddCommand(CreateListCommand())
@@ -0,0 +1,390 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mini-clis/task-list/task"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// CreateListsCommand represents the lists command and its subcommands
+func CreateListsCommand() *cobra.Command {
+
+	listsCmd := &cobra.Command{
+		Use:   "lists",
+		Short: "See the lists your tasks are kept in",
+		Long: `Tasks are kept in named lists like work or home.
+Every store starts with a default list.
+Commands work on the active list unless you pass --list.
+Without a subcommand this shows every list and how many tasks are in it.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			registry, error := listRegistry(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			groups := task.GroupByList(tasks, registry.Names())
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			if plain {
+
+				type listSummary struct {
+					Name   string `json:"name"`
+					Active bool   `json:"active"`
+					Tasks  int    `json:"tasks"`
+				}
+
+				summaries, error := json.Marshal(lo.Map(groups, func(group task.ListGroup, index int) listSummary {
+					return listSummary{
+						Name:   group.List,
+						Active: group.List == registry.ActiveList(),
+						Tasks:  len(group.Tasks),
+					}
+				}))
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), string(summaries))
+
+				return nil
+			}
+
+			for _, group := range groups {
+
+				name := lo.Ternary(
+					group.List == registry.ActiveList(),
+					pterm.FgGreen.Sprintf("* %s", group.List),
+					fmt.Sprintf("  %s", group.List),
+				)
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", name, pterm.FgGray.Sprintf("(%d)", len(group.Tasks)))
+			}
+
+			return nil
+		},
+	}
+
+	listsCmd.AddCommand(
+		createListsCreateCommand(),
+		createListsRenameCommand(),
+		createListsDeleteCommand(),
+		createListsUseCommand(),
+		createListsShowCommand(),
+	)
+
+	return listsCmd
+}
+
+func createListsCreateCommand() *cobra.Command {
+
+	return &cobra.Command{
+		Use:          "create <name>",
+		Short:        "Create a list",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			registry, error := listRegistry(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			name, error := registry.Create(args[0])
+
+			if error != nil {
+				return error
+			}
+
+			if error := registry.Save(); error != nil {
+				return error
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Created the %s list\n", name)
+
+			return nil
+		},
+	}
+}
+
+// createListsRenameCommand moves every task in the old list to the new one.
+func createListsRenameCommand() *cobra.Command {
+
+	return &cobra.Command{
+		Use:          "rename <name> <new name>",
+		Short:        "Rename a list",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			registry, error := listRegistry(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			name, error := registry.Rename(args[0], args[1])
+
+			if error != nil {
+				return error
+			}
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			renamedTasks := lo.Map(tasks, func(item task.Task, index int) task.Task {
+
+				if item.List != args[0] {
+					return item
+				}
+
+				item.List = name
+
+				return item.Revise()
+			})
+
+			if error := store.Save(renamedTasks); error != nil {
+				return error
+			}
+
+			if error := registry.Save(); error != nil {
+				return error
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Renamed the %s list to %s\n", args[0], name)
+
+			return nil
+		},
+	}
+}
+
+// createListsDeleteCommand refuses to delete a list that still has tasks unless --force is passed.
+func createListsDeleteCommand() *cobra.Command {
+
+	deleteCmd := &cobra.Command{
+		Use:          "delete <name>",
+		Short:        "Delete a list",
+		Long:         "Deletes a list. A list with tasks in it is only deleted along with its tasks when you pass --force.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			force, error := cmd.Flags().GetBool(FORCE)
+
+			if error != nil {
+				return error
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			registry, error := listRegistry(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			name := args[0]
+
+			if error := registry.Delete(name); error != nil {
+				return error
+			}
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			listTasks := task.InList(tasks, name)
+
+			if len(listTasks) > 0 && !force {
+				return fmt.Errorf(
+					"The %s list has %d tasks pass --%s to delete them too or move them first",
+					name,
+					len(listTasks),
+					FORCE,
+				)
+			}
+
+			if len(listTasks) > 0 {
+
+				deletedIds := lo.Map(listTasks, func(item task.Task, index int) string { return item.Id() })
+
+				if error := task.SaveChanges(store, task.Reparent(tasks, deletedIds), deletedIds); error != nil {
+					return error
+				}
+			}
+
+			if error := registry.Save(); error != nil {
+				return error
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted the %s list\n", name)
+
+			return nil
+		},
+	}
+
+	deleteCmd.Flags().Bool(FORCE, false, "Delete the tasks in the list too")
+
+	return deleteCmd
+}
+
+func createListsUseCommand() *cobra.Command {
+
+	return &cobra.Command{
+		Use:               "use <name>",
+		Short:             "Make a list the active list",
+		Args:              cobra.ExactArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: completeLists,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			registry, error := listRegistry(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			if error := registry.Use(args[0]); error != nil {
+				return error
+			}
+
+			if error := registry.Save(); error != nil {
+				return error
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Now using the %s list\n", args[0])
+
+			return nil
+		},
+	}
+}
+
+func createListsShowCommand() *cobra.Command {
+
+	return &cobra.Command{
+		Use:          "show",
+		Short:        "Show the active list",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			list, error := activeList(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), list)
+
+			return nil
+		},
+	}
+}
+
+// completeLists suggests the lists that exist in the store.
+func completeLists(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+	registry, error := listRegistry(cmd)
+
+	if error != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return lo.Filter(registry.Names(), func(item string, index int) bool {
+		return strings.HasPrefix(item, toComplete)
+	}), cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(CreateListsCommand())
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mini-clis/task-list/task"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+const DEFAULT_LOG_LIMIT = 20
+
+// CreateLogCommand represents the log command
+func CreateLogCommand() *cobra.Command {
+
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "See the recent changes to your tasks",
+		Long: `Lists the commands that added, edited or deleted tasks, most recent first,
+with when they ran and how many tasks they changed.
+Commands you undid are marked so you can see what redo would bring back.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			limit, error := cmd.Flags().GetInt(LIMIT)
+
+			if error != nil {
+				return error
+			}
+
+			_, journal, error := storeAndJournal(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			operations, error := journal.Operations()
+
+			if error != nil {
+				return error
+			}
+
+			slices.Reverse(operations)
+
+			if limit > 0 && len(operations) > limit {
+				operations = operations[:limit]
+			}
+
+			if len(operations) == 0 {
+
+				fmt.Fprint(cmd.OutOrStdout(), "Nothing has changed yet")
+
+				return nil
+			}
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			if plain {
+
+				operationsAsJSON, error := task.MarshallOperations(operations)
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), operationsAsJSON)
+
+				return nil
+			}
+
+			for _, operation := range operations {
+
+				line := fmt.Sprintf(
+					"%s %s %s",
+					pterm.FgGray.Sprint(operation.Time.Format("2006-01-02 15:04:05")),
+					operation.Command,
+					pterm.FgGray.Sprint(describeChanges(operation)),
+				)
+
+				if operation.Undone {
+					line += pterm.FgYellow.Sprint(" undone")
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+
+			return nil
+		},
+	}
+
+	logCmd.Flags().IntP(LIMIT, "n", DEFAULT_LOG_LIMIT, "Only show this many changes")
+
+	return logCmd
+}
+
+// describeChanges counts the tasks an operation added, edited and deleted.
+// A task changed more than once counts once by how it ended up.
+func describeChanges(operation task.Operation) string {
+
+	counts := lo.CountValuesBy(lo.Keys(lo.GroupBy(operation.Changes, task.Change.Id)), func(id string) string {
+
+		changes := lo.Filter(operation.Changes, func(change task.Change, index int) bool { return change.Id() == id })
+
+		return lo.If(changes[0].Before == nil, "added").
+			ElseIf(changes[len(changes)-1].After == nil, "deleted").
+			Else("edited")
+	})
+
+	parts := lo.FilterMap([]string{"added", "edited", "deleted"}, func(kind string, index int) (string, bool) {
+		return fmt.Sprintf("%d %s", counts[kind], kind), counts[kind] > 0
+	})
+
+	return fmt.Sprintf("(%s)", strings.Join(parts, ", "))
+}
+
+func init() {
+	rootCmd.AddCommand(CreateLogCommand())
+}
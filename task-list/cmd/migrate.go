@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mini-clis/task-list/task"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+const DRY_RUN = "dry-run"
+
+// CreateMigrateCommand represents the migrate command
+func CreateMigrateCommand() *cobra.Command {
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the task file to the current schema version",
+		Long: `Task files written by older versions of task-list are upgraded in memory every time they are read.
+This command writes the upgraded file back so the upgrade only happens once.
+Pass --dry-run to see which migrations would run without changing anything.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			dryRun, error := cmd.Flags().GetBool(DRY_RUN)
+
+			if error != nil {
+				return error
+			}
+
+			store, _, error := storeAndJournal(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			migrator, ok := store.(task.Migrator)
+
+			if !ok {
+				return fmt.Errorf("This backend doesn't store a schema so there's nothing to migrate")
+			}
+
+			var plan task.MigrationPlan
+
+			if dryRun {
+
+				plan, error = migrator.PlanMigration()
+
+			} else {
+
+				unlock, lockError := lockStore(cmd, store)
+
+				if lockError != nil {
+					return lockError
+				}
+
+				defer unlock()
+
+				plan, error = migrator.Migrate()
+			}
+
+			if error != nil {
+				return error
+			}
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			if plain {
+
+				planAsJSON, error := json.Marshal(plan)
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), string(planAsJSON))
+
+				return nil
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), renderMigrationPlan(plan, dryRun))
+
+			return nil
+		},
+	}
+
+	migrateCmd.Flags().Bool(DRY_RUN, false, "Show what would change without writing anything")
+
+	return migrateCmd
+}
+
+func renderMigrationPlan(plan task.MigrationPlan, dryRun bool) string {
+
+	if plan.UpToDate() {
+		return pterm.FgGreen.Sprintf("The task file is already at schema version %d\n", plan.To)
+	}
+
+	heading := fmt.Sprintf(
+		"%s %d tasks from schema version %d to %d\n",
+		lo.Ternary(dryRun, "Would migrate", "Migrated"),
+		plan.Tasks,
+		plan.From,
+		plan.To,
+	)
+
+	output := pterm.FgCyan.Sprint(heading)
+
+	for _, migration := range plan.Migrations {
+		output += fmt.Sprintf("  %s %s\n", pterm.FgYellow.Sprintf("v%d", migration.Version), migration.Description)
+	}
+
+	return output
+}
+
+func init() {
+	rootCmd.AddCommand(CreateMigrateCommand())
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+const TO = "to"
+
+// CreateMoveCommand represents the move command
+func CreateMoveCommand() *cobra.Command {
+
+	moveCmd := &cobra.Command{
+		Use:   "move <id>",
+		Short: "Move a task to another list",
+		Long: `Moves a task to the list passed to --to.
+Its subtasks move with it so they stay in the same list as their parent.
+A subtask can't be moved on its own, move the task at the top of it instead.
+`,
+		Args:              cobra.ExactArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: completeTaskIds,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			to, error := cmd.Flags().GetString(TO)
+
+			if error != nil {
+				return error
+			}
+
+			registry, error := listRegistry(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			if !registry.Has(to) {
+				return fmt.Errorf(
+					"%w %s %w %s create it with task-list lists create %s",
+					custom_errors.InvalidFlag,
+					TO,
+					task.ErrListNotFound,
+					to,
+					to,
+				)
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			movedTask, error := resolveTask(cmd, store, args[0])
+
+			if error != nil {
+				return error
+			}
+
+			id := movedTask.Id()
+
+			if parent, ok := lo.Find(tasks, func(item task.Task) bool { return item.Id() == movedTask.ParentId }); ok {
+				return custom_errors.CreateInvalidArgumentErrorWithMessage(
+					fmt.Sprintf("%s is a subtask of %s so it moves with it, move %s instead", movedTask.Title, parent.Title, parent.Id()),
+				)
+			}
+
+			for _, item := range append([]task.Task{movedTask}, task.Descendants(tasks, id)...) {
+
+				if item.List == to {
+					continue
+				}
+
+				item.List = to
+
+				// The task gets the next number in the list it moves to.
+				if error := store.Put(item.ClearNumber().Revise()); error != nil {
+					return error
+				}
+			}
+
+			movedTask, error = store.Get(id)
+
+			if error != nil {
+				return error
+			}
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			if plain {
+
+				taskAsJSON, error := movedTask.ToJSON()
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprint(cmd.OutOrStdout(), taskAsJSON)
+
+				return nil
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %s to the %s list\n", movedTask.Title, to)
+
+			return nil
+		},
+	}
+
+	moveCmd.Flags().String(TO, "", "The list to move the task to")
+	moveCmd.MarkFlagRequired(TO)
+	moveCmd.RegisterFlagCompletionFunc(TO, completeLists)
+
+	return moveCmd
+}
+
+func init() {
+	rootCmd.AddCommand(CreateMoveCommand())
+}
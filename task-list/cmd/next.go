@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mini-clis/task-list/task"
+	"github.com/spf13/cobra"
+)
+
+const LIMIT = "limit"
+
+// CreateNextCommand represents the next command
+func CreateNextCommand() *cobra.Command {
+
+	nextCmd := &cobra.Command{
+		Use:   "next",
+		Short: "See the tasks you can work on next",
+		Long: `Lists the incomplete tasks in the active list that aren't waiting on another incomplete task.
+The highest priority tasks come first and tasks with the same priority are ordered by when they're due.
+Pass --limit to only see the first few.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			limit, error := cmd.Flags().GetInt(LIMIT)
+
+			if error != nil {
+				return error
+			}
+
+			out, error := commandOutput(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			list, error := activeList(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			// Blockers can be in other lists so every task is passed to Next.
+			nextTasks := task.InList(task.Next(tasks), list)
+
+			if limit > 0 && len(nextTasks) > limit {
+				nextTasks = nextTasks[:limit]
+			}
+
+			if out.passed() {
+				return out.print(cmd.OutOrStdout(), printedTasks{tasks: nextTasks, all: tasks}, false)
+			}
+
+			if len(nextTasks) == 0 {
+
+				fmt.Fprint(cmd.OutOrStdout(), "There are no tasks you can work on next")
+
+				return nil
+			}
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			if plain {
+
+				stringifiedTasks, error := task.MarshallTasks(nextTasks)
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), stringifiedTasks)
+
+				return nil
+			}
+
+			columns, _ := parseTableColumns(defaultTableColumns)
+
+			fmt.Fprint(
+				cmd.OutOrStdout(),
+				renderTaskTable(nextTasks, tasks, time.Now(), columns, terminalWidth(cmd.OutOrStdout())),
+			)
+
+			return nil
+		},
+	}
+
+	nextCmd.Flags().IntP(LIMIT, "n", 0, "Only show this many tasks")
+
+	printsTasks(nextCmd)
+
+	return nextCmd
+}
+
+func init() {
+	rootCmd.AddCommand(CreateNextCommand())
+}
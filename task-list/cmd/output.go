@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const OUTPUT = "output"
+
+const TEMPLATE = "template"
+
+const NDJSON = "ndjson"
+
+const YAML = "yaml"
+
+// OUTPUTS are what add, edit, delete, list and next can print with --output.
+var OUTPUTS = []string{JSON, NDJSON, YAML, task.CSV, TABLE, TEMPLATE}
+
+// printable is what a command prints when --output is passed.
+type printable interface {
+	// records are a JSON object for every item for json, ndjson and yaml.
+	records() ([]json.RawMessage, error)
+	// values are what --template is executed with, one at a time.
+	values() []any
+	writeCSV(writer io.Writer) error
+	table(width int) string
+}
+
+// printedTasks shows tasks with what's worked out from every task in the store
+// like their short ids and blockers.
+type printedTasks struct {
+	tasks   []task.Task
+	all     []task.Task
+	columns []tableColumn
+}
+
+func (self printedTasks) records() ([]json.RawMessage, error) {
+
+	listing := task.NewListing(self.all)
+
+	records := []json.RawMessage{}
+
+	for _, item := range self.tasks {
+
+		taskAsJSON, error := listing.ToJSON(item)
+
+		if error != nil {
+			return nil, error
+		}
+
+		records = append(records, json.RawMessage(taskAsJSON))
+	}
+
+	return records, nil
+}
+
+func (self printedTasks) values() []any {
+
+	return lo.ToAnySlice(self.tasks)
+}
+
+func (self printedTasks) writeCSV(writer io.Writer) error {
+
+	return task.WriteCSV(writer, self.tasks, task.CSVColumns())
+}
+
+func (self printedTasks) table(width int) string {
+
+	columns := self.columns
+
+	if len(columns) == 0 {
+		columns, _ = parseTableColumns(defaultTableColumns)
+	}
+
+	return renderTaskTable(self.tasks, self.all, time.Now(), columns, width)
+}
+
+// deletedTask is what delete reports about each task it removed.
+type deletedTask struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type deletedTasks []deletedTask
+
+func newDeletedTasks(tasks []task.Task) deletedTasks {
+
+	return lo.Map(tasks, func(item task.Task, index int) deletedTask {
+		return deletedTask{Id: item.Id(), Title: item.Title}
+	})
+}
+
+func (self deletedTasks) records() ([]json.RawMessage, error) {
+
+	records := []json.RawMessage{}
+
+	for _, item := range self {
+
+		record, error := json.Marshal(item)
+
+		if error != nil {
+			return nil, error
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (self deletedTasks) values() []any {
+
+	return lo.ToAnySlice(self)
+}
+
+func (self deletedTasks) writeCSV(writer io.Writer) error {
+
+	csvWriter := csv.NewWriter(writer)
+
+	csvWriter.Write([]string{"id", "title"})
+
+	for _, item := range self {
+		csvWriter.Write([]string{item.Id, item.Title})
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+func (self deletedTasks) table(width int) string {
+
+	deletedTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			return lipgloss.NewStyle().Padding(0, 1).Bold(row == table.HeaderRow)
+		}).
+		Headers("ID", "TITLE").
+		Rows(lo.Map(self, func(item deletedTask, index int) []string { return []string{item.Id, item.Title} })...)
+
+	if lipgloss.Width(deletedTable.String()) > width {
+		deletedTable.Width(width)
+	}
+
+	return deletedTable.String() + "\n"
+}
+
+// output is how a command was asked to print what it did.
+// An empty format means --output wasn't passed so the command prints the way it always has.
+type output struct {
+	format   string
+	template *template.Template
+}
+
+// printsTasks lets a command take --output and --template.
+func printsTasks(command *cobra.Command) {
+
+	command.Annotations = lo.Assign(command.Annotations, map[string]string{OUTPUT: "true"})
+}
+
+// checkOutputFlags stops --output and --template being passed to a command that can't print tasks with them.
+func checkOutputFlags(cmd *cobra.Command) error {
+
+	if cmd.Annotations[OUTPUT] != "" {
+		return nil
+	}
+
+	for _, name := range []custom_errors.FlagName{OUTPUT, TEMPLATE} {
+
+		if value, _ := cmd.Flags().GetString(string(name)); value != "" {
+			return custom_errors.CreateInvalidFlagErrorWithMessage(name, "only works with add, edit, delete, list and next")
+		}
+	}
+
+	return nil
+}
+
+// commandOutput reads --output and --template.
+// Passing --template on its own is the same as --output template.
+func commandOutput(cmd *cobra.Command) (output, error) {
+
+	format, error := cmd.Flags().GetString(OUTPUT)
+
+	if error != nil {
+		return output{}, error
+	}
+
+	text, error := cmd.Flags().GetString(TEMPLATE)
+
+	if error != nil {
+		return output{}, error
+	}
+
+	if format == "" && text != "" {
+		format = TEMPLATE
+	}
+
+	if format != TEMPLATE {
+
+		if text != "" {
+			return output{}, custom_errors.CreateInvalidFlagErrorWithMessage(TEMPLATE, "only works with --output template")
+		}
+
+		return output{format: format}, nil
+	}
+
+	if text == "" {
+		return output{}, custom_errors.CreateInvalidFlagErrorWithMessage(TEMPLATE, "is needed for --output template")
+	}
+
+	parsedTemplate, error := template.New(TEMPLATE).Option("missingkey=error").Parse(text)
+
+	if error != nil {
+		return output{}, fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, TEMPLATE, error)
+	}
+
+	return output{format: TEMPLATE, template: parsedTemplate}, nil
+}
+
+func (self output) passed() bool {
+
+	return self.format != ""
+}
+
+// print writes items in the format passed to --output.
+// A single item is printed as an object instead of a list for json and yaml.
+func (self output) print(writer io.Writer, items printable, single bool) error {
+
+	switch self.format {
+	case JSON, NDJSON, YAML:
+		return self.printRecords(writer, items, single)
+	case task.CSV:
+		return items.writeCSV(writer)
+	case TABLE:
+		_, error := fmt.Fprint(writer, items.table(terminalWidth(writer)))
+		return error
+	case TEMPLATE:
+		for _, value := range items.values() {
+
+			if error := self.template.Execute(writer, value); error != nil {
+				return error
+			}
+
+			fmt.Fprintln(writer)
+		}
+	}
+
+	return nil
+}
+
+func (self output) printRecords(writer io.Writer, items printable, single bool) error {
+
+	records, error := items.records()
+
+	if error != nil {
+		return error
+	}
+
+	if self.format == NDJSON {
+
+		for _, record := range records {
+			fmt.Fprintln(writer, string(record))
+		}
+
+		return nil
+	}
+
+	var document any = records
+
+	if single && len(records) == 1 {
+		document = records[0]
+	}
+
+	documentAsJSON, error := json.Marshal(document)
+
+	if error != nil {
+		return error
+	}
+
+	if self.format == JSON {
+		_, error := fmt.Fprintln(writer, string(documentAsJSON))
+		return error
+	}
+
+	return writeYAML(writer, documentAsJSON)
+}
+
+// writeYAML turns JSON into YAML keeping the order of the keys.
+// JSON is YAML already so it's read as a node and written back in block style.
+func writeYAML(writer io.Writer, documentAsJSON []byte) error {
+
+	var node yaml.Node
+
+	if error := yaml.Unmarshal(documentAsJSON, &node); error != nil {
+		return error
+	}
+
+	var clearStyle func(node *yaml.Node)
+
+	clearStyle = func(node *yaml.Node) {
+
+		node.Style = 0
+
+		for _, child := range node.Content {
+			clearStyle(child)
+		}
+	}
+
+	clearStyle(&node)
+
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+
+	encoder.SetIndent(2)
+
+	if error := encoder.Encode(&node); error != nil {
+		return error
+	}
+
+	_, error := writer.Write(buffer.Bytes())
+
+	return error
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/mini-clis/task-list/task"
+	"github.com/spf13/cobra"
+)
+
+// CreateRedoCommand represents the redo command
+func CreateRedoCommand() *cobra.Command {
+
+	redoCmd := &cobra.Command{
+		Use:   "redo",
+		Short: "Redo the last change you undid",
+		Long: `Makes the changes of the command you undid last again.
+Once you add, edit or delete something after undoing there's nothing left to redo.
+A task that was changed after the undo is left alone unless you pass --force.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			force, error := cmd.Flags().GetBool(FORCE)
+
+			if error != nil {
+				return error
+			}
+
+			store, journal, error := storeAndJournal(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			redone, error := journal.Redo(store, force)
+
+			if error != nil {
+				return error
+			}
+
+			return printOperations(cmd, []task.Operation{redone}, "Redid")
+		},
+	}
+
+	redoCmd.Flags().Bool(FORCE, false, "Redo even when a task was changed since it was undone")
+
+	return redoCmd
+}
+
+func init() {
+	rootCmd.AddCommand(CreateRedoCommand())
+}
@@ -4,18 +4,44 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/config"
+	"github.com/mini-clis/task-list/flags"
+	"github.com/mini-clis/task-list/query"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 const PLAIN = "plain"
 
+const STORE = "store"
+
+const LOCK_TIMEOUT = "lock-timeout"
+
+const LIST = "list"
+
+const WHERE = "where"
+
+var outputFlag = flags.NewUnionFlag(OUTPUTS, OUTPUT)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "task-list",
 	Short: "This is a task list CLI app",
 	Long:  `This is an app that allows you to CREATE, READ, UPDATE, and DELETE tasks.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+
+		return checkOutputFlags(cmd)
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -27,6 +53,311 @@ func RootCmd() *cobra.Command {
 
 }
 
+// taskStore opens the backend named in the config.
+// File backends resolve their location using the --store flag,
+// the TASK_LIST_FILE env var, the config file and the XDG data dir in that order.
+// Every change made through it is recorded in the journal so it can be undone.
+func taskStore(cmd *cobra.Command) (task.TaskStore, error) {
+
+	store, journal, error := storeAndJournal(cmd)
+
+	if error != nil {
+		return nil, error
+	}
+
+	return task.NewJournaledStore(store, journal, commandLine(cmd)), nil
+
+}
+
+// storeAndJournal opens the store without recording changes
+// for commands like undo that work on the journal themselves.
+func storeAndJournal(cmd *cobra.Command) (task.TaskStore, task.Journal, error) {
+
+	backend, path, error := storeLocation(cmd)
+
+	if error != nil {
+		return nil, task.Journal{}, error
+	}
+
+	return task.OpenTaskStore(backend, path), task.OpenJournal(task.JournalPath(path)), nil
+
+}
+
+// commandLine describes a command the way it was typed for the journal
+// leaving out the flags that only say where the store is or how to print.
+func commandLine(cmd *cobra.Command) string {
+
+	quote := func(value string) string {
+		return lo.Ternary(value == "" || strings.ContainsAny(value, " \t\"'"), strconv.Quote(value), value)
+	}
+
+	parts := append(
+		strings.Fields(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())),
+		lo.Map(cmd.Flags().Args(), func(argument string, index int) string { return quote(argument) })...,
+	)
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+
+		if lo.Contains([]string{PLAIN, OUTPUT, TEMPLATE, STORE, LOCK_TIMEOUT}, flag.Name) {
+			return
+		}
+
+		if flag.Value.Type() == "bool" {
+			parts = append(parts, lo.Ternary(flag.Value.String() == "true", "--"+flag.Name, "--"+flag.Name+"=false"))
+			return
+		}
+
+		values := []string{flag.Value.String()}
+
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			values = sliceValue.GetSlice()
+		}
+
+		for _, value := range values {
+			parts = append(parts, "--"+flag.Name, quote(value))
+		}
+	})
+
+	return strings.Join(parts, " ")
+
+}
+
+// storeLocation is empty for the memory backend.
+func storeLocation(cmd *cobra.Command) (task.Backend, string, error) {
+
+	store, error := cmd.Flags().GetString(STORE)
+
+	if error != nil {
+		return "", "", error
+	}
+
+	config, error := config.Load()
+
+	if error != nil {
+		return "", "", error
+	}
+
+	backend, error := task.ParseBackend(config.Backend)
+
+	if error != nil {
+		return "", "", error
+	}
+
+	if backend == task.MEMORY_BACKEND {
+		return backend, "", nil
+	}
+
+	path, error := task.ResolveStoragePath(backend, store, config.Store)
+
+	if error != nil {
+		return "", "", error
+	}
+
+	return backend, path, nil
+
+}
+
+// listRegistry reads the lists that belong to the task store.
+func listRegistry(cmd *cobra.Command) (task.ListRegistry, error) {
+
+	_, path, error := storeLocation(cmd)
+
+	if error != nil {
+		return task.ListRegistry{}, error
+	}
+
+	return task.ReadListRegistry(task.ListRegistryPath(path))
+
+}
+
+// activeList is the list passed to --list or the one picked with lists use.
+func activeList(cmd *cobra.Command) (string, error) {
+
+	list, error := cmd.Flags().GetString(LIST)
+
+	if error != nil {
+		return "", error
+	}
+
+	registry, error := listRegistry(cmd)
+
+	if error != nil {
+		return "", error
+	}
+
+	return registry.Resolve(list)
+
+}
+
+// whereQuery parses the query passed to --where.
+// It returns false when the flag wasn't passed.
+func whereQuery(cmd *cobra.Command) (query.Query, bool, error) {
+
+	if !cmd.Flags().Changed(WHERE) {
+		return query.Query{}, false, nil
+	}
+
+	input, error := cmd.Flags().GetString(WHERE)
+
+	if error != nil {
+		return query.Query{}, false, error
+	}
+
+	parsedQuery, error := query.Parse(input)
+
+	if error != nil {
+		return query.Query{}, false, fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, WHERE, error)
+	}
+
+	return parsedQuery, true, nil
+}
+
+// idOrWhere takes an id unless --where picks the tasks.
+func idOrWhere(cmd *cobra.Command, args []string) error {
+
+	if cmd.Flags().Changed(WHERE) {
+		return cobra.NoArgs(cmd, args)
+	}
+
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// whereFlagUsage lists the fields so --help is enough to write a query.
+func whereFlagUsage(action string) string {
+
+	return fmt.Sprintf(
+		"%s tasks matching a query like 'priority >= medium and not complete' using %s",
+		action,
+		strings.Join(query.FieldNames(), ", "),
+	)
+}
+
+// resolveTask finds a task by its id, the start of its id or its number in the active list.
+func resolveTask(cmd *cobra.Command, store task.TaskStore, reference string) (task.Task, error) {
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return task.Task{}, error
+	}
+
+	list, error := activeList(cmd)
+
+	if error != nil {
+		return task.Task{}, error
+	}
+
+	found, error := task.Resolve(tasks, reference, list)
+
+	if errors.Is(error, task.ErrTaskNotFound) {
+		return found, fmt.Errorf(
+			"%w Task with this id wasn't found %s",
+			custom_errors.InvalidArgument,
+			reference,
+		)
+	}
+
+	return found, error
+
+}
+
+// completeTaskIds suggests short ids with the task title as the description.
+func completeTaskIds(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+	store, error := taskStore(cmd)
+
+	if error != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	shortIds := task.ShortIds(tasks)
+
+	return lo.FilterMap(tasks, func(item task.Task, index int) (string, bool) {
+		return fmt.Sprintf("%s\t%s", shortIds[item.Id()], item.Title), strings.HasPrefix(item.Id(), toComplete)
+	}), cobra.ShellCompDirectiveNoFileComp
+
+}
+
+// parentCompletionRule reads the parent completion rule from the config.
+func parentCompletionRule() (task.CompletionRule, error) {
+
+	config, error := config.Load()
+
+	if error != nil {
+		return "", error
+	}
+
+	return task.ParseCompletionRule(config.ParentCompletion)
+
+}
+
+// taskWorkflow reads the extra statuses from the config.
+func taskWorkflow() (task.Workflow, error) {
+
+	config, error := config.Load()
+
+	if error != nil {
+		return nil, error
+	}
+
+	return task.NewWorkflow(config.Statuses)
+
+}
+
+// completeStatuses suggests every status in the workflow.
+func completeStatuses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+	workflow, error := taskWorkflow()
+
+	if error != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return workflow.Values(), cobra.ShellCompDirectiveNoFileComp
+
+}
+
+// completeTags suggests the tags that are already used in the store.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+	store, error := taskStore(cmd)
+
+	if error != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return lo.Filter(task.AllTags(tasks), func(item string, index int) bool {
+		return strings.HasPrefix(item, toComplete)
+	}), cobra.ShellCompDirectiveNoFileComp
+
+}
+
+// lockStore guards a read modify write cycle.
+// It waits up to --lock-timeout for other task-list processes to finish.
+func lockStore(cmd *cobra.Command, store task.TaskStore) (func() error, error) {
+
+	timeout, error := cmd.Flags().GetDuration(LOCK_TIMEOUT)
+
+	if error != nil {
+		return nil, error
+	}
+
+	return store.Lock(timeout)
+
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -50,4 +381,46 @@ func init() {
 		false,
 		"This is for normal output",
 	)
+
+	rootCmd.PersistentFlags().VarP(
+		&outputFlag,
+		OUTPUT,
+		"o",
+		fmt.Sprintf("How add, edit, delete, list and next print what they did one of %s", strings.Join(OUTPUTS, ",")),
+	)
+
+	rootCmd.RegisterFlagCompletionFunc(
+		OUTPUT,
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+			return OUTPUTS, cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	rootCmd.PersistentFlags().String(
+		TEMPLATE,
+		"",
+		"A Go template printed for every task like '{{.Title}} ({{.Priority}})'",
+	)
+
+	rootCmd.PersistentFlags().String(
+		STORE,
+		"",
+		"The file where tasks are stored (default is $XDG_DATA_HOME/task-list/task-list.json)",
+	)
+
+	rootCmd.PersistentFlags().Duration(
+		LOCK_TIMEOUT,
+		5*time.Second,
+		"How long to wait for another task-list process to release the task file",
+	)
+
+	rootCmd.PersistentFlags().StringP(
+		LIST,
+		"L",
+		"",
+		"The list to work on (default is the active list)",
+	)
+
+	rootCmd.RegisterFlagCompletionFunc(LIST, completeLists)
 }
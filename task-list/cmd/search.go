@@ -0,0 +1,168 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mini-clis/task-list/task"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// CreateSearchCommand represents the search command
+func CreateSearchCommand() *cobra.Command {
+
+	searchCmd := &cobra.Command{
+		Use:   "search <terms>",
+		Short: "Search the titles and descriptions of your tasks",
+		Long: `Finds the tasks whose title or description matches every term you pass.
+Terms don't need to be exact so deplyo and dply both find deploy.
+The best matches come first and the words that matched are highlighted.
+Pass --plain to get JSON with the score of every task and where each term matched.
+Only tasks in the active list are searched unless you pass --all-lists.
+`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			limit, error := cmd.Flags().GetInt(LIMIT)
+
+			if error != nil {
+				return error
+			}
+
+			allLists, error := cmd.Flags().GetBool(ALL_LISTS)
+
+			if error != nil {
+				return error
+			}
+
+			store, error := taskStore(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			tasks, error := store.Load()
+
+			if error != nil {
+				return error
+			}
+
+			searchedTasks := tasks
+
+			if !allLists {
+
+				list, error := activeList(cmd)
+
+				if error != nil {
+					return error
+				}
+
+				searchedTasks = task.InList(tasks, list)
+			}
+
+			results := task.Search(searchedTasks, task.SearchTerms(strings.Join(args, " ")))
+
+			if limit > 0 && len(results) > limit {
+				results = results[:limit]
+			}
+
+			listing := task.NewListing(tasks)
+
+			plain, error := cmd.Flags().GetBool(PLAIN)
+
+			if error != nil {
+				return error
+			}
+
+			// Scripts get an empty array when nothing matches.
+			if plain {
+
+				resultsAsJSON, error := listing.MarshallSearchResults(results)
+
+				if error != nil {
+					return error
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), resultsAsJSON)
+
+				return nil
+			}
+
+			if len(results) == 0 {
+
+				fmt.Fprintln(cmd.OutOrStdout(), "There are no tasks that match these terms")
+
+				return nil
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), renderSearchResults(results, listing))
+
+			return nil
+		},
+	}
+
+	searchCmd.Flags().IntP(LIMIT, "n", 0, "Only show this many tasks")
+	searchCmd.Flags().Bool(ALL_LISTS, false, "Search the tasks in every list")
+
+	return searchCmd
+}
+
+var searchHighlight = pterm.NewStyle(pterm.FgYellow, pterm.Bold)
+
+// highlight styles the words of a field that a term matched.
+func highlight(text, field string, matches []task.Span) string {
+
+	spans := lo.Filter(matches, func(span task.Span, index int) bool { return span.Field == field })
+
+	slices.SortFunc(spans, func(a, b task.Span) int { return cmp.Compare(a.Start, b.Start) })
+
+	var builder strings.Builder
+
+	end := 0
+
+	for _, span := range spans {
+		builder.WriteString(text[end:span.Start])
+		builder.WriteString(searchHighlight.Sprint(text[span.Start:span.End]))
+		end = span.End
+	}
+
+	builder.WriteString(text[end:])
+
+	return builder.String()
+}
+
+// renderSearchResults shows the description under the title when a term matched it.
+func renderSearchResults(results []task.SearchResult, listing task.Listing) string {
+
+	var builder strings.Builder
+
+	for _, result := range results {
+
+		fmt.Fprintf(
+			&builder,
+			"[%s] %s %s %s\n",
+			lo.Ternary(result.Done(), "x", " "),
+			highlight(result.Title, task.TITLE_FIELD, result.Matches),
+			listing.ShortId(result.Task),
+			pterm.FgGray.Sprintf("%.2f", result.Score),
+		)
+
+		if lo.ContainsBy(result.Matches, func(span task.Span) bool { return span.Field == task.DESCRIPTION_FIELD }) {
+			fmt.Fprintf(&builder, "    %s\n", highlight(result.Description, task.DESCRIPTION_FIELD, result.Matches))
+		}
+	}
+
+	return builder.String()
+}
+
+func init() {
+	rootCmd.AddCommand(CreateSearchCommand())
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+	"golang.org/x/term"
+)
+
+const TABLE = "table"
+
+const JSON = "json"
+
+// The widest a table gets when it isn't printed to a terminal and COLUMNS isn't set.
+const defaultTableWidth = 120
+
+// Titles are never wrapped narrower than this. The table truncates them instead.
+const minTitleWidth = 12
+
+// tableColumn is a task field that can be shown in the list table.
+type tableColumn struct {
+	name  string
+	value func(item task.Task, listing task.Listing, now time.Time) string
+}
+
+var tableColumns = []tableColumn{
+	{"id", func(item task.Task, listing task.Listing, now time.Time) string { return listing.ShortId(item) }},
+	{"number", func(item task.Task, listing task.Listing, now time.Time) string {
+		return lo.Ternary(item.Number() == 0, "", fmt.Sprintf("#%d", item.Number()))
+	}},
+	{"title", func(item task.Task, listing task.Listing, now time.Time) string { return item.Title }},
+	{"priority", func(item task.Task, listing task.Listing, now time.Time) string { return priorityBadge(item) }},
+	{"status", func(item task.Task, listing task.Listing, now time.Time) string {
+		return taskStatus(item, listing, now)
+	}},
+	{"due", func(item task.Task, listing task.Listing, now time.Time) string { return formatDueDate(item.Due) }},
+	{"tags", func(item task.Task, listing task.Listing, now time.Time) string { return strings.Join(item.Tags, ", ") }},
+	{"list", func(item task.Task, listing task.Listing, now time.Time) string { return item.List }},
+	{"created", func(item task.Task, listing task.Listing, now time.Time) string {
+		return relativeTime(time.UnixMicro(item.CreatedAt()), now)
+	}},
+	{"updated", func(item task.Task, listing task.Listing, now time.Time) string {
+		return relativeTime(item.UpdatedAt, now)
+	}},
+}
+
+// defaultTableColumns are shown when --columns isn't passed.
+var defaultTableColumns = []string{"id", "title", "priority", "status", "created", "updated"}
+
+func tableColumnNames() []string {
+
+	return lo.Map(tableColumns, func(column tableColumn, index int) string { return column.name })
+}
+
+// parseTableColumns checks every name is a column and keeps the order they were passed in.
+func parseTableColumns(names []string) ([]tableColumn, error) {
+
+	columns := []tableColumn{}
+
+	for _, name := range lo.Uniq(names) {
+
+		column, ok := lo.Find(tableColumns, func(column tableColumn) bool {
+			return strings.EqualFold(column.name, strings.TrimSpace(name))
+		})
+
+		if !ok {
+			return nil, fmt.Errorf("%q isn't a column try one of %s", name, strings.Join(tableColumnNames(), ", "))
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+var priorityBadgeStyles = map[string]lipgloss.Style{
+	task.HIGH.Value():   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("1")),
+	task.MEDIUM.Value(): lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("3")),
+	task.LOW.Value():    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("4")),
+}
+
+func priorityBadge(item task.Task) string {
+
+	return priorityBadgeStyles[item.Priority.Value()].Render(fmt.Sprintf(" %s ", strings.ToUpper(item.Priority.Value())))
+}
+
+var statusStyles = map[string]lipgloss.Style{
+	task.DONE.Value():    lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+	"overdue":            lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true),
+	task.BLOCKED.Value(): lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+	task.DOING.Value():   lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+}
+
+// taskStatus is the status of the task unless it's overdue or waiting for another task.
+// A task that's waiting names the tasks it waits on.
+func taskStatus(item task.Task, listing task.Listing, now time.Time) string {
+
+	status := item.Status.Value()
+
+	blockers := lo.Map(listing.Blockers(item), func(blocker task.Task, index int) string { return blocker.Title })
+
+	switch {
+	case item.Done():
+		return statusStyles[status].Render(status)
+	case item.Overdue(now):
+		status = "overdue"
+	case len(blockers) > 0:
+		status = task.BLOCKED.Value()
+	}
+
+	if len(blockers) == 0 {
+		return statusStyles[status].Render(status)
+	}
+
+	blockedBy := fmt.Sprintf("by %s", strings.Join(blockers, ", "))
+
+	if status != task.BLOCKED.Value() {
+		blockedBy = fmt.Sprintf("%s %s", task.BLOCKED.Value(), blockedBy)
+	}
+
+	return statusStyles[status].Render(status) + " " + statusStyles[task.BLOCKED.Value()].Render(blockedBy)
+}
+
+// relativeTime says how long ago a moment was like 5m ago or 3d ago.
+// Anything older than a month shows its date.
+func relativeTime(moment time.Time, now time.Time) string {
+
+	elapsed := now.Sub(moment)
+
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	}
+
+	return moment.Format(time.DateOnly)
+}
+
+// terminalWidth is the width of the terminal the writer prints to.
+// Output that isn't going to a terminal uses COLUMNS and then defaultTableWidth.
+func terminalWidth(writer io.Writer) int {
+
+	if file, ok := writer.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+
+		if width, _, error := term.GetSize(int(file.Fd())); error == nil && width > 0 {
+			return width
+		}
+	}
+
+	if width, error := strconv.Atoi(os.Getenv("COLUMNS")); error == nil && width > 0 {
+		return width
+	}
+
+	return defaultTableWidth
+}
+
+// renderTaskTable draws a row for every task that fits in width.
+// Long titles wrap onto more lines and any other cell that doesn't fit is truncated.
+// Blockers are looked up in all.
+func renderTaskTable(tasks []task.Task, all []task.Task, now time.Time, columns []tableColumn, width int) string {
+
+	listing := task.NewListing(all)
+
+	headers := lo.Map(columns, func(column tableColumn, index int) string { return strings.ToUpper(column.name) })
+
+	rows := lo.Map(tasks, func(item task.Task, index int) []string {
+		return lo.Map(columns, func(column tableColumn, index int) string { return column.value(item, listing, now) })
+	})
+
+	// Every cell has a space either side and every column a border to its left plus one on the right.
+	widthOf := func(index int) int {
+		return lo.Max(append(
+			lo.Map(rows, func(row []string, _ int) int { return lipgloss.Width(row[index]) }),
+			lipgloss.Width(headers[index]),
+		)) + 2
+	}
+
+	if titleIndex := lo.IndexOf(lo.Map(columns, func(column tableColumn, index int) string { return column.name }), "title"); titleIndex != -1 {
+
+		otherWidths := lo.Sum(lo.FilterMap(columns, func(column tableColumn, index int) (int, bool) {
+			return widthOf(index), index != titleIndex
+		}))
+
+		titleWidth := width - otherWidths - len(columns) - 1 - 2
+
+		if widthOf(titleIndex)-2 > titleWidth && titleWidth >= minTitleWidth {
+			for _, row := range rows {
+				row[titleIndex] = lipgloss.NewStyle().Width(titleWidth).Render(row[titleIndex])
+			}
+		}
+	}
+
+	taskTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))).
+		StyleFunc(func(row, col int) lipgloss.Style {
+
+			style := lipgloss.NewStyle().Padding(0, 1)
+
+			if row == table.HeaderRow {
+				return style.Bold(true)
+			}
+
+			return style
+		}).
+		Headers(headers...).
+		Rows(rows...)
+
+	if lipgloss.Width(taskTable.String()) > width {
+		taskTable.Width(width)
+	}
+
+	return taskTable.String() + "\n"
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mini-clis/task-list/task"
+	"github.com/spf13/cobra"
+)
+
+// CreateTUICommand represents the tui command
+func CreateTUICommand() *cobra.Command {
+
+	tuiCmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse and change your tasks in a full screen app",
+		Long: `Opens the tasks in the active list in a full screen app with the selected task shown next to them.
+Move with j and k or the arrow keys, g and G jump to the first and last task.
+Press space to complete a task, e to edit it, d to delete it, / to filter and s to change the sort.
+Tasks can be clicked to select them and their checkbox to complete them.
+Every change is saved to the task store straight away and can be undone with task-list undo.
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			browser, error := NewTaskBrowser(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			_, error = tea.NewProgram(browser, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+
+			return error
+		},
+	}
+
+	return tuiCmd
+}
+
+// browserStore changes tasks the way the commands do.
+// Every change is its own operation in the journal so they can be undone one at a time.
+type browserStore struct {
+	cmd     *cobra.Command
+	store   task.TaskStore
+	journal task.Journal
+}
+
+func (self browserStore) load() ([]task.Task, error) {
+
+	return self.store.Load()
+}
+
+func (self browserStore) journaled(action string, item task.Task) task.TaskStore {
+
+	return task.NewJournaledStore(self.store, self.journal, fmt.Sprintf("tui %s %s", action, item.Id()))
+}
+
+// save stores the edited task unless someone else changed it since base was read.
+// Completing a task applies the parent completion rule and moves a repeating task on.
+func (self browserStore) save(action string, edited, base task.Task) error {
+
+	store := self.journaled(action, base)
+
+	unlock, error := lockStore(self.cmd, store)
+
+	if error != nil {
+		return error
+	}
+
+	defer unlock()
+
+	if _, error := task.CheckRevision(store, base.Id(), base.Revision()); error != nil {
+		return error
+	}
+
+	_, _, error = saveEditedTask(store, edited.Revise(), base)
+
+	return error
+}
+
+// delete removes a task and moves its subtasks up to its parent
+// unless someone else changed it since item was read.
+func (self browserStore) delete(item task.Task) error {
+
+	store := self.journaled("delete", item)
+
+	unlock, error := lockStore(self.cmd, store)
+
+	if error != nil {
+		return error
+	}
+
+	defer unlock()
+
+	if _, error := task.CheckRevision(store, item.Id(), item.Revision()); error != nil {
+		return error
+	}
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return error
+	}
+
+	return task.SaveChanges(store, task.Reparent(tasks, []string{item.Id()}), []string{item.Id()})
+}
+
+func init() {
+	rootCmd.AddCommand(CreateTUICommand())
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/task"
+	"github.com/spf13/cobra"
+)
+
+// CreateUndoCommand represents the undo command
+func CreateUndoCommand() *cobra.Command {
+
+	undoCmd := &cobra.Command{
+		Use:   "undo [n]",
+		Short: "Undo the last change to your tasks",
+		Long: `Undoes the last command that added, edited or deleted tasks.
+Pass a number to undo that many commands, most recent first.
+A task that was changed again after the command is left alone unless you pass --force.
+Run task-list log to see what can be undone and task-list redo to change your mind.
+`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			count := 1
+
+			if len(args) == 1 {
+
+				parsedCount, error := strconv.Atoi(args[0])
+
+				if error != nil || parsedCount < 1 {
+					return custom_errors.CreateInvalidArgumentErrorWithMessage(
+						fmt.Sprintf("%s isn't a number of commands to undo", args[0]),
+					)
+				}
+
+				count = parsedCount
+			}
+
+			force, error := cmd.Flags().GetBool(FORCE)
+
+			if error != nil {
+				return error
+			}
+
+			store, journal, error := storeAndJournal(cmd)
+
+			if error != nil {
+				return error
+			}
+
+			unlock, error := lockStore(cmd, store)
+
+			if error != nil {
+				return error
+			}
+
+			defer unlock()
+
+			undone, error := journal.Undo(store, count, force)
+
+			if printError := printOperations(cmd, undone, "Undid"); printError != nil {
+				return printError
+			}
+
+			return error
+		},
+	}
+
+	undoCmd.Flags().Bool(FORCE, false, "Undo even when a task was changed again since")
+
+	return undoCmd
+}
+
+// printOperations shows what undo or redo did.
+func printOperations(cmd *cobra.Command, operations []task.Operation, verb string) error {
+
+	plain, error := cmd.Flags().GetBool(PLAIN)
+
+	if error != nil {
+		return error
+	}
+
+	if plain {
+
+		if len(operations) == 0 {
+			return nil
+		}
+
+		operationsAsJSON, error := task.MarshallOperations(operations)
+
+		if error != nil {
+			return error
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), operationsAsJSON)
+
+		return nil
+	}
+
+	for _, operation := range operations {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s %s\n", verb, operation.Command, describeChanges(operation))
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(CreateUndoCommand())
+}
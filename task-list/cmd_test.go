@@ -7,12 +7,19 @@ import (
 	"log"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mini-clis/shared/custom_errors"
 	. "github.com/mini-clis/task-list/cmd"
+	"github.com/mini-clis/task-list/config"
+	"github.com/mini-clis/task-list/query"
 	"github.com/mini-clis/task-list/task"
 	. "github.com/onsi/ginkgo/v2"
 	"github.com/samber/lo"
@@ -21,13 +28,24 @@ import (
 )
 
 type mockPersistedTask struct {
-	Id          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Priority    string `json:"priority"`
-	Complete    bool   `json:"complete"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+	Id          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Priority    string            `json:"priority"`
+	Status      string            `json:"status,omitempty"`
+	Complete    bool              `json:"complete"`
+	CreatedAt   int64             `json:"createdAt"`
+	UpdatedAt   int64             `json:"updatedAt"`
+	Revision    int               `json:"revision"`
+	Due         int64             `json:"due,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	ParentId    string            `json:"parentId,omitempty"`
+	BlockedBy   []string          `json:"blockedBy,omitempty"`
+	Repeat      string            `json:"repeat,omitempty"`
+	List        string            `json:"list,omitempty"`
+	Number      int               `json:"number,omitempty"`
+	ShortId     string            `json:"shortId,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 // Helper Functions
@@ -48,23 +66,46 @@ var executeCommand = func(cmd *cobra.Command, args ...string) (string, error) {
 	return buffer.String(), err
 }
 
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+var stripAnsi = func(text string) string {
+	return ansiPattern.ReplaceAllString(text, "")
+}
+
 var createFlag = func(flagName string) string {
 	return fmt.Sprintf("--%s", flagName)
 }
 
-var getMockPersistedTasks = func() ([]mockPersistedTask, error) {
+type mockEnvelope struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	Tasks         []mockPersistedTask `json:"tasks"`
+}
+
+// Seeded files are bare arrays and files written by the app are envelopes.
+var unmarshalMockPersistedTasks = func(data []byte) ([]mockPersistedTask, error) {
 	var tasks []mockPersistedTask
 
-	data, err := os.ReadFile(task.TASK_LIST_STORAGE_PATH)
-	if err != nil {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err := json.Unmarshal(data, &tasks)
 		return tasks, err
 	}
 
-	if err := json.Unmarshal(data, &tasks); err != nil {
+	var envelope mockEnvelope
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
 		return tasks, err
 	}
 
-	return tasks, nil
+	return envelope.Tasks, nil
+}
+
+var getMockPersistedTasks = func() ([]mockPersistedTask, error) {
+	data, err := os.ReadFile(storagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalMockPersistedTasks(data)
 }
 
 var getMockPersistedTaskBasedOnOutput = func(output string, err error) (mockPersistedTask, error) {
@@ -92,7 +133,7 @@ var getRandomPersistedTask = func(tasks []mockPersistedTask) (mockPersistedTask,
 
 var seedTasks = func() {
 	file, err := os.OpenFile(
-		task.TASK_LIST_STORAGE_PATH,
+		storagePath,
 		os.O_TRUNC|os.O_WRONLY,
 		os.ModePerm,
 	)
@@ -147,19 +188,62 @@ var _ = Describe("Cmd", func() {
 
 	seedTasks()
 
+	addCommands := func() {
+		rootCmd.AddCommand(
+			CreateListCommand(),
+			CreateEditCmd(),
+			CreateAddCmd(),
+			CreateDeleteCommand(),
+			CreateMigrateCommand(),
+			CreateNextCommand(),
+			CreateListsCommand(),
+			CreateMoveCommand(),
+			CreateSearchCommand(),
+			CreateUndoCommand(),
+			CreateRedoCommand(),
+			CreateLogCommand(),
+			CreateHistoryCommand(),
+			CreateImportCommand(),
+			CreateExportCommand(),
+		)
+	}
+
+	// --output and --template live on the root command so they aren't reset along with the commands.
+	resetOutputFlags := func() {
+		assert.NoError(rootCmd.PersistentFlags().Set(OUTPUT, ""))
+		assert.NoError(rootCmd.PersistentFlags().Set(TEMPLATE, ""))
+	}
+
+	// Flag values stick to a command after it runs
+	// so tests that run the same command twice need fresh ones.
+	resetCommands := func() {
+		rootCmd.ResetCommands()
+		addCommands()
+		resetOutputFlags()
+	}
+
+	// run executes a command with fresh flags.
+	run := func(args ...string) (string, error) {
+		resetCommands()
+		return executeCommand(rootCmd, args...)
+	}
+
+	// useStorage keeps the tasks of a test in a file of their own and returns its path.
+	useStorage := func(name string) string {
+		path := filepath.Join(GinkgoT().TempDir(), name)
+		GinkgoT().Setenv(task.STORAGE_PATH_ENV, path)
+		return path
+	}
+
 	BeforeEach(func() {
 		if len(rootCmd.Commands()) == 0 {
-			rootCmd.AddCommand(
-				CreateListCommand(),
-				CreateEditCmd(),
-				CreateAddCmd(),
-				CreateDeleteCommand(),
-			)
+			addCommands()
 		}
 	})
 
 	AfterEach(func() {
 		rootCmd.ResetCommands()
+		resetOutputFlags()
 	})
 
 	Context("List", func() {
@@ -307,18 +391,2010 @@ var _ = Describe("Cmd", func() {
 
 	})
 
-	Context("Editing tasks", Ordered, func() {
-		var mockTasks []mockPersistedTask
-		var mockTask mockPersistedTask
+	Context("Storage location", func() {
+		AfterEach(func() {
+			rootCmd.PersistentFlags().Set(STORE, "")
+		})
 
-		BeforeAll(func() {
-			tasks, err := getMockPersistedTasks()
+		It("creates the file passed to --store and its parent directories", func() {
+			customStoragePath := filepath.Join(GinkgoT().TempDir(), "nested", "tasks.json")
+
+			newTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Stored somewhere else", createFlag(STORE), customStoragePath),
+			)
 			assert.NoError(err)
-			assert.NotEmpty(tasks)
-			mockTasks = tasks
+			assert.NotEmpty(newTask)
+
+			data, err := os.ReadFile(customStoragePath)
+			assert.NoError(err)
+
+			tasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+			assert.Len(tasks, 1)
+			assert.Equal(newTask.Id, tasks[0].Id)
+		})
+
+		It("falls back to the XDG data dir when nothing else is set", func() {
+			dataHome := GinkgoT().TempDir()
+			GinkgoT().Setenv(task.STORAGE_PATH_ENV, "")
+			GinkgoT().Setenv("XDG_DATA_HOME", dataHome)
+
+			path, err := task.ResolveStoragePath(task.JSON_BACKEND, "", "")
+			assert.NoError(err)
+			assert.Equal(filepath.Join(dataHome, "task-list", task.STORAGE_FILE_NAME), path)
+			assert.FileExists(path)
+		})
+
+		It("uses the config default before the XDG data dir", func() {
+			configDefault := filepath.Join(GinkgoT().TempDir(), "from-config.json")
+			GinkgoT().Setenv(task.STORAGE_PATH_ENV, "")
+
+			path, err := task.ResolveStoragePath(task.JSON_BACKEND, "", configDefault)
+			assert.NoError(err)
+			assert.Equal(configDefault, path)
+		})
+	})
+
+	Context("Task stores", func() {
+		type StoreCase struct {
+			Name string
+			Open func() task.TaskStore
+		}
+
+		lo.ForEach([]StoreCase{
+			{
+				Name: "json",
+				Open: func() task.TaskStore {
+					path := filepath.Join(GinkgoT().TempDir(), "tasks.json")
+					assert.NoError(task.EnsureStorageFile(task.JSON_BACKEND, path))
+					return task.OpenTaskStore(task.JSON_BACKEND, path)
+				},
+			},
+			{
+				Name: "jsonl",
+				Open: func() task.TaskStore {
+					path := filepath.Join(GinkgoT().TempDir(), "tasks.jsonl")
+					assert.NoError(task.EnsureStorageFile(task.JSON_LINES_BACKEND, path))
+					return task.OpenTaskStore(task.JSON_LINES_BACKEND, path)
+				},
+			},
+			{
+				Name: "memory",
+				Open: func() task.TaskStore {
+					return task.OpenTaskStore(task.MEMORY_BACKEND, "")
+				},
+			},
+		}, func(storeCase StoreCase, index int) {
+			It(fmt.Sprintf("puts, gets and deletes tasks with the %s backend", storeCase.Name), func() {
+				store := storeCase.Open()
+
+				first := task.NewTask("First", "")
+				second := task.NewTask("Second", "")
+
+				assert.NoError(store.Put(first))
+				assert.NoError(store.Put(second))
+
+				first.Title = "First edited"
+				assert.NoError(store.Put(first))
+
+				tasks, err := store.Load()
+				assert.NoError(err)
+				assert.Equal(
+					[]string{"Second", "First edited"},
+					lo.Map(tasks, func(item task.Task, index int) string { return item.Title }),
+				)
+
+				found, err := store.Get(second.Id())
+				assert.NoError(err)
+				assert.Equal(second.Title, found.Title)
+
+				assert.NoError(store.Delete(second.Id()))
+
+				_, err = store.Get(second.Id())
+				assert.ErrorIs(err, task.ErrTaskNotFound)
+
+				assert.NoError(store.Save(tasks))
+
+				tasks, err = store.Load()
+				assert.NoError(err)
+				assert.Len(tasks, 2)
+				assert.Equal(second.Id(), tasks[0].Id())
+			})
+		})
+
+		It("selects the backend from the config file", func() {
+			configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+			customStoragePath := filepath.Join(GinkgoT().TempDir(), "tasks.jsonl")
+			GinkgoT().Setenv(config.CONFIG_PATH_ENV, configPath)
+			GinkgoT().Setenv(task.STORAGE_PATH_ENV, customStoragePath)
+
+			assert.NoError(os.WriteFile(configPath, []byte(`{"backend": "jsonl"}`), 0o600))
+
+			newTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Appended"),
+			)
+			assert.NoError(err)
+
+			data, err := os.ReadFile(customStoragePath)
+			assert.NoError(err)
+			assert.Contains(string(data), newTask.Id)
+			assert.Equal(1, bytes.Count(data, []byte("\n")))
+		})
+
+		It("appends a delete to the JSON Lines file when no subtasks move", func() {
+			configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+			GinkgoT().Setenv(config.CONFIG_PATH_ENV, configPath)
+			jsonLinesPath := useStorage("tasks.jsonl")
+
+			assert.NoError(os.WriteFile(configPath, []byte(`{"backend": "jsonl"}`), 0o600))
+
+			first, err := getMockPersistedTaskBasedOnOutput(run("add", "First"))
+			assert.NoError(err)
+
+			_, err = run("add", "Second")
+			assert.NoError(err)
+
+			_, err = run("delete", first.Id)
+			assert.NoError(err)
+
+			data, err := os.ReadFile(jsonLinesPath)
+			assert.NoError(err)
+
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			assert.Len(lines, 3)
+			assert.Contains(lines[2], `"delete"`)
+		})
+
+		It("appends what undo and redo change to the JSON Lines file", func() {
+			configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+			GinkgoT().Setenv(config.CONFIG_PATH_ENV, configPath)
+			jsonLinesPath := useStorage("tasks.jsonl")
+
+			assert.NoError(os.WriteFile(configPath, []byte(`{"backend": "jsonl"}`), 0o600))
+
+			_, err := run("add", "First")
+			assert.NoError(err)
+
+			_, err = run("undo")
+			assert.NoError(err)
+
+			_, err = run("redo")
+			assert.NoError(err)
+
+			data, err := os.ReadFile(jsonLinesPath)
+			assert.NoError(err)
+
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			assert.Len(lines, 3)
+			assert.Contains(lines[1], `"delete"`)
+			assert.Contains(lines[2], "First")
+		})
+	})
+
+	Context("Crash safe writes", func() {
+		AfterEach(func() {
+			rootCmd.PersistentFlags().Set(LOCK_TIMEOUT, "5s")
+		})
+
+		It("replaces the task file with 0600 permissions and leaves no temp files", func() {
+			dir := GinkgoT().TempDir()
+			path := filepath.Join(dir, "tasks.json")
+			assert.NoError(task.EnsureStorageFile(task.JSON_BACKEND, path))
+
+			assert.NoError(task.SaveTasks(path, []task.Task{task.NewTask("Saved", "")}))
+
+			info, err := os.Stat(path)
+			assert.NoError(err)
+			assert.Equal(os.FileMode(0o600), info.Mode().Perm())
+
+			entries, err := os.ReadDir(dir)
+			assert.NoError(err)
+			assert.Equal(
+				[]string{"tasks.json"},
+				lo.Map(entries, func(item os.DirEntry, index int) string { return item.Name() }),
+			)
+		})
+
+		It("returns a lock error when another process holds the task file", func() {
+			store := task.OpenTaskStore(task.JSON_BACKEND, storagePath)
+
+			unlock, err := store.Lock(0)
+			assert.NoError(err)
+			defer unlock()
+
+			_, err = store.Lock(0)
+			assert.ErrorIs(err, task.ErrLocked)
+
+			output, err := executeCommand(rootCmd, "add", "Blocked", createFlag(LOCK_TIMEOUT), "100ms")
+			assert.ErrorIs(err, task.ErrLocked)
+			assert.Empty(output)
+		})
+
+		It("waits for the lock to be released", func() {
+			store := task.OpenTaskStore(task.JSON_BACKEND, storagePath)
+
+			unlock, err := store.Lock(0)
+			assert.NoError(err)
+
+			time.AfterFunc(100*time.Millisecond, func() { unlock() })
+
+			secondUnlock, err := store.Lock(2 * time.Second)
+			assert.NoError(err)
+			assert.NoError(secondUnlock())
+		})
+	})
+
+	Context("Revisions", func() {
+		It("bumps the revision when a task is edited", func() {
+			newTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Revisioned"),
+			)
+			assert.NoError(err)
+			assert.Equal(1, newTask.Revision)
+
+			editedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "edit", newTask.Id, createFlag(TITLE), "Revisioned again"),
+			)
+			assert.NoError(err)
+			assert.Equal(2, editedTask.Revision)
+		})
+
+		It("reports a conflict when the stored revision moved on", func() {
+			store := task.NewMemoryStore()
+			base := task.NewTask("Shared", "")
+			assert.NoError(store.Put(base))
+
+			theirs := base
+			theirs.Priority = task.HIGH
+			assert.NoError(store.Put(theirs.Revise()))
+
+			latest, err := task.CheckRevision(store, base.Id(), base.Revision())
+
+			var conflictError task.ConflictError
+			assert.ErrorAs(err, &conflictError)
+			assert.ErrorIs(err, task.ErrConflict)
+			assert.Equal(base.Revision()+1, latest.Revision())
+			assert.Equal(task.HIGH, conflictError.Theirs.Priority)
+		})
+
+		It("merges changes that don't clash and lists the ones that do", func() {
+			base := task.NewTask("Title", "Description")
+
+			yours := base
+			yours.Title = "Your title"
+			yours.Description = "Your description"
+
+			theirs := base
+			theirs.Description = "Their description"
+			theirs.Status = task.DONE
+			theirs = theirs.Revise()
+
+			merged, conflicts := task.Merge(base, yours, theirs)
+
+			assert.Equal([]string{"description"}, conflicts)
+			assert.Equal("Your title", merged.Title)
+			assert.Equal("Their description", merged.Description)
+			assert.True(merged.Done())
+			assert.Equal(theirs.Revision()+1, merged.Revision())
+
+			merged.TakeField("description", yours)
+			assert.Equal("Your description", merged.Description)
+		})
+
+		It("refuses to delete a task that changed since the given revision", func() {
+			newTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Delete me carefully"),
+			)
+			assert.NoError(err)
+
+			_, err = executeCommand(rootCmd, "edit", newTask.Id, createFlag(PRIORITY), task.HIGH.Value())
+			assert.NoError(err)
+
+			output, err := executeCommand(rootCmd, "delete", newTask.Id, createFlag(REVISION), "1")
+			assert.ErrorIs(err, task.ErrConflict)
+			assert.Empty(output)
+
+			output, err = executeCommand(rootCmd, "delete", newTask.Id, createFlag(REVISION), "2")
+			assert.NoError(err)
+			assert.NotEmpty(output)
+		})
+	})
+
+	Context("Schema migrations", func() {
+		var legacyStoragePath string
+
+		BeforeEach(func() {
+			legacyStoragePath = useStorage("legacy.json")
+
+			data, err := json.Marshal([]mockPersistedTask{
+				{Id: gofakeit.UUID(), Title: "Legacy", Priority: task.LOW.Value()},
+			})
+			assert.NoError(err)
+			assert.NoError(os.WriteFile(legacyStoragePath, data, 0o600))
+		})
+
+		It("reads the bare array written before the schema was versioned", func() {
+			output, err := executeCommand(rootCmd, "list")
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+			assert.Len(tasks, 1)
+			assert.Equal("Legacy", tasks[0].Title)
+		})
+
+		It("shows the plan without writing anything when --dry-run is passed", func() {
+			before, err := os.ReadFile(legacyStoragePath)
+			assert.NoError(err)
+
+			output, err := executeCommand(rootCmd, "migrate", createFlag(DRY_RUN))
+			assert.NoError(err)
+
+			var plan task.MigrationPlan
+			assert.NoError(json.Unmarshal([]byte(output), &plan))
+			assert.Equal(0, plan.From)
+			assert.Equal(task.CurrentSchemaVersion(), plan.To)
+			assert.Equal(1, plan.Tasks)
+			assert.NotEmpty(plan.Migrations)
+
+			after, err := os.ReadFile(legacyStoragePath)
+			assert.NoError(err)
+			assert.Equal(before, after)
+		})
+
+		It("rewrites the file inside an envelope", func() {
+			_, err := executeCommand(rootCmd, "migrate")
+			assert.NoError(err)
+
+			data, err := os.ReadFile(legacyStoragePath)
+			assert.NoError(err)
+
+			var envelope mockEnvelope
+			assert.NoError(json.Unmarshal(data, &envelope))
+			assert.Equal(task.CurrentSchemaVersion(), envelope.SchemaVersion)
+			assert.Len(envelope.Tasks, 1)
+
+			output, err := executeCommand(rootCmd, "migrate", createFlag(DRY_RUN))
+			assert.NoError(err)
+
+			var plan task.MigrationPlan
+			assert.NoError(json.Unmarshal([]byte(output), &plan))
+			assert.True(plan.UpToDate())
+		})
+		It("turns the complete flag into a status", func() {
+			data, err := json.Marshal([]mockPersistedTask{
+				{Id: gofakeit.UUID(), Title: "Finished", Priority: task.LOW.Value(), Complete: true},
+				{Id: gofakeit.UUID(), Title: "Started", Priority: task.LOW.Value()},
+			})
+			assert.NoError(err)
+			assert.NoError(os.WriteFile(legacyStoragePath, data, 0o600))
+
+			_, err = executeCommand(rootCmd, "migrate")
+			assert.NoError(err)
+
+			data, err = os.ReadFile(legacyStoragePath)
+			assert.NoError(err)
+
+			tasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+
+			statuses := lo.SliceToMap(tasks, func(item mockPersistedTask) (string, string) { return item.Title, item.Status })
+			assert.Equal(map[string]string{"Finished": "done", "Started": "todo"}, statuses)
+		})
+	})
+
+	Context("Due dates", func() {
+		var pastTask, futureTask mockPersistedTask
+
+		BeforeEach(func() {
+			useStorage("due.json")
+
+			var err error
+
+			pastTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Past", createFlag(DUE), "2001-01-01"),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+
+			_, err = executeCommand(rootCmd, "add", "Whenever")
+			assert.NoError(err)
+
+			futureTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Future", createFlag(DUE), "2999-01-01T09:00:00Z"),
+			)
+			assert.NoError(err)
+		})
+
+		extractTitles := func(output string, err error) []string {
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			return lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title })
+		}
+
+		It("stores the due date passed to add", func() {
+			expected, err := time.ParseInLocation(time.DateOnly, "2001-01-01", time.Local)
+			assert.NoError(err)
+			assert.Equal(expected.UnixMicro(), pastTask.Due)
+		})
+
+		It("rejects a due date that isn't a date", func() {
+			output, err := executeCommand(rootCmd, "add", "Never", createFlag(DUE), "someday")
+			assert.Error(err)
+			assert.Empty(output)
+		})
+
+		It("filters overdue tasks", func() {
+			assert.Equal(
+				[]string{"Past"},
+				extractTitles(executeCommand(rootCmd, "list", createFlag(OVERDUE))),
+			)
+		})
+
+		It("filters tasks by due date range", func() {
+			assert.Equal(
+				[]string{"Past"},
+				extractTitles(executeCommand(rootCmd, "list", createFlag(DUE_BEFORE), "2500-01-01")),
+			)
+
+			resetCommands()
+
+			assert.Equal(
+				[]string{"Future"},
+				extractTitles(executeCommand(rootCmd, "list", createFlag(DUE_AFTER), "2500-01-01")),
+			)
+		})
+
+		It("sorts by due date with undated tasks last", func() {
+			assert.Equal(
+				[]string{"Past", "Future", "Whenever"},
+				extractTitles(executeCommand(rootCmd, "list", createFlag(SORT_DATE), DUE)),
+			)
+		})
+
+		It("removes a due date when edit is passed none", func() {
+			editedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "edit", futureTask.Id, createFlag(DUE), "none"),
+			)
+			assert.NoError(err)
+			assert.Zero(editedTask.Due)
+			assert.NotEqual(futureTask.UpdatedAt, editedTask.UpdatedAt)
 		})
+	})
+
+	Context("Tags", func() {
+		var errandTask mockPersistedTask
+
+		extractTitles := func(output string, err error) []string {
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			return lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title })
+		}
 
 		BeforeEach(func() {
+			useStorage("tags.json")
+
+			var err error
+
+			_, err = executeCommand(rootCmd, "add", "Both", createFlag(TAG), "work", createFlag(TAG), "urgent")
+			assert.NoError(err)
+
+			resetCommands()
+
+			_, err = executeCommand(rootCmd, "add", "Work", createFlag(TAG), "work")
+			assert.NoError(err)
+
+			resetCommands()
+
+			errandTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Errand", createFlag(TAG), "home", createFlag(TAG), "home"),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+		})
+
+		It("stores each tag once", func() {
+			assert.Equal([]string{"home"}, errandTask.Tags)
+		})
+
+		It("rejects tags with spaces", func() {
+			_, err := executeCommand(rootCmd, "add", "Bad", createFlag(TAG), "two words")
+			assert.Error(err)
+		})
+
+		It("filters tasks with any of the tags by default", func() {
+			assert.ElementsMatch(
+				[]string{"Both", "Work", "Errand"},
+				extractTitles(executeCommand(rootCmd, "list", createFlag(TAG), "work", createFlag(TAG), "home")),
+			)
+		})
+
+		It("filters tasks with all of the tags", func() {
+			assert.Equal(
+				[]string{"Both"},
+				extractTitles(executeCommand(
+					rootCmd, "list", createFlag(TAG), "work", createFlag(TAG), "urgent", createFlag(TAG_MATCH), task.ALL,
+				)),
+			)
+		})
+
+		It("filters out tasks with a tag", func() {
+			assert.ElementsMatch(
+				[]string{"Work", "Errand"},
+				extractTitles(executeCommand(rootCmd, "list", createFlag(NOT_TAG), "urgent")),
+			)
+		})
+
+		It("adds and removes tags when editing", func() {
+			editedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(
+					rootCmd, "edit", errandTask.Id, createFlag(ADD_TAG), "weekend", createFlag(REMOVE_TAG), "home",
+				),
+			)
+			assert.NoError(err)
+			assert.Equal([]string{"weekend"}, editedTask.Tags)
+			assert.Equal(errandTask.Revision+1, editedTask.Revision)
+		})
+
+		It("completes the tags that are already in the store", func() {
+			var buffer bytes.Buffer
+			rootCmd.SetOut(&buffer)
+			rootCmd.SetArgs([]string{cobra.ShellCompRequestCmd, "list", createFlag(TAG), "u"})
+
+			assert.NoError(rootCmd.Execute())
+			assert.Equal([]string{"urgent", ":4"}, strings.Fields(buffer.String())[:2])
+		})
+	})
+
+	Context("Subtasks", func() {
+		var parentTask, childTask, grandchildTask mockPersistedTask
+		var subtasksPath string
+
+		type mockTreeNode struct {
+			mockPersistedTask
+			Done     int            `json:"done"`
+			Total    int            `json:"total"`
+			Children []mockTreeNode `json:"children"`
+		}
+
+		readSubtasks := func() ([]mockPersistedTask, error) {
+			data, err := os.ReadFile(subtasksPath)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return unmarshalMockPersistedTasks(data)
+		}
+
+		setParentCompletion := func(rule task.CompletionRule) {
+			configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+			GinkgoT().Setenv(config.CONFIG_PATH_ENV, configPath)
+
+			assert.NoError(os.WriteFile(configPath, []byte(fmt.Sprintf(`{"parentCompletion": %q}`, rule)), 0o600))
+		}
+
+		BeforeEach(func() {
+			subtasksPath = useStorage("subtasks.json")
+
+			var err error
+
+			parentTask, err = getMockPersistedTaskBasedOnOutput(executeCommand(rootCmd, "add", "Release"))
+			assert.NoError(err)
+
+			resetCommands()
+
+			childTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Write notes", createFlag(PARENT), parentTask.Id),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+
+			grandchildTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Proofread", createFlag(PARENT), childTask.Id),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+		})
+
+		It("stores the parent of a subtask", func() {
+			assert.Equal(parentTask.Id, childTask.ParentId)
+		})
+
+		It("rejects a parent that doesn't exist", func() {
+			_, err := executeCommand(rootCmd, "add", "Orphan", createFlag(PARENT), "missing")
+			assert.Error(err)
+		})
+
+		It("nests subtasks and rolls up their counts", func() {
+			_, err := executeCommand(rootCmd, "edit", grandchildTask.Id, createFlag(COMPLETE), "true")
+			assert.NoError(err)
+
+			resetCommands()
+
+			output, err := executeCommand(rootCmd, "list", createFlag(TREE))
+			assert.NoError(err)
+
+			var nodes []mockTreeNode
+			assert.NoError(json.Unmarshal([]byte(output), &nodes))
+
+			assert.Len(nodes, 1)
+			assert.Equal(parentTask.Id, nodes[0].Id)
+			assert.Equal([2]int{1, 2}, [2]int{nodes[0].Done, nodes[0].Total})
+			assert.Equal(grandchildTask.Id, nodes[0].Children[0].Children[0].Id)
+		})
+
+		It("refuses to complete a parent with open subtasks when the rule is block", func() {
+			setParentCompletion(task.BLOCK)
+
+			_, err := executeCommand(rootCmd, "edit", parentTask.Id, createFlag(COMPLETE), "true")
+			assert.ErrorIs(err, task.ErrOpenChildren)
+
+			mockTasks, err := readSubtasks()
+			assert.NoError(err)
+			assert.False(lo.SomeBy(mockTasks, func(item mockPersistedTask) bool { return item.Complete }))
+		})
+
+		It("completes every subtask when the rule is cascade", func() {
+			setParentCompletion(task.CASCADE)
+
+			_, err := executeCommand(rootCmd, "edit", parentTask.Id, createFlag(COMPLETE), "true")
+			assert.NoError(err)
+
+			mockTasks, err := readSubtasks()
+			assert.NoError(err)
+			assert.True(lo.EveryBy(mockTasks, func(item mockPersistedTask) bool { return item.Complete }))
+		})
+
+		It("moves subtasks up to the parent of a deleted task", func() {
+			_, err := executeCommand(rootCmd, "delete", childTask.Id)
+			assert.NoError(err)
+
+			mockTasks, err := readSubtasks()
+			assert.NoError(err)
+
+			movedTask, found := lo.Find(mockTasks, func(item mockPersistedTask) bool { return item.Id == grandchildTask.Id })
+			assert.True(found)
+			assert.Equal(parentTask.Id, movedTask.ParentId)
+		})
+
+		It("undoes deleting a task and moving its subtasks in one step", func() {
+			_, err := executeCommand(rootCmd, "delete", childTask.Id)
+			assert.NoError(err)
+
+			resetCommands()
+
+			_, err = executeCommand(rootCmd, "undo")
+			assert.NoError(err)
+
+			mockTasks, err := readSubtasks()
+			assert.NoError(err)
+			assert.Len(mockTasks, 3)
+
+			restoredTask, found := lo.Find(mockTasks, func(item mockPersistedTask) bool { return item.Id == grandchildTask.Id })
+			assert.True(found)
+			assert.Equal(childTask.Id, restoredTask.ParentId)
+		})
+
+		It("deletes subtasks along with their parent when cascading", func() {
+			_, err := executeCommand(rootCmd, "delete", parentTask.Id, createFlag(CHILDREN), CASCADE)
+			assert.NoError(err)
+
+			mockTasks, err := readSubtasks()
+			assert.NoError(err)
+			assert.Empty(mockTasks)
+		})
+	})
+
+	Context("Dependencies", func() {
+		var designTask, buildTask, shipTask mockPersistedTask
+
+		type mockListedTask struct {
+			mockPersistedTask
+			Blocked  bool `json:"blocked"`
+			Blockers []struct {
+				Id    string `json:"id"`
+				Title string `json:"title"`
+			} `json:"blockers"`
+		}
+
+		extractTitles := func(output string, err error) []string {
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			return lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title })
+		}
+
+		BeforeEach(func() {
+			useStorage("dependencies.json")
+
+			var err error
+
+			designTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Design", createFlag(PRIORITY), "low"),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+
+			buildTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Build", createFlag(PRIORITY), "high"),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+
+			shipTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "add", "Ship", createFlag(PRIORITY), "medium", createFlag(DUE), "2999-01-01"),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+
+			_, err = executeCommand(rootCmd, "edit", buildTask.Id, createFlag(BLOCKED_BY), designTask.Id)
+			assert.NoError(err)
+
+			resetCommands()
+		})
+
+		It("stores the tasks a task is blocked by", func() {
+			editedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "edit", shipTask.Id, createFlag(BLOCKED_BY), buildTask.Id),
+			)
+			assert.NoError(err)
+			assert.Equal([]string{buildTask.Id}, editedTask.BlockedBy)
+		})
+
+		It("names the blockers in the status column of the table", func() {
+			output, err := run("--plain=false", "list")
+			assert.NoError(err)
+
+			row, found := lo.Find(strings.Split(stripAnsi(output), "\n"), func(line string) bool {
+				return strings.Contains(line, "Build")
+			})
+			assert.True(found)
+			assert.Contains(row, "blocked by Design")
+		})
+
+		It("rejects a dependency that would close a cycle", func() {
+			_, err := executeCommand(rootCmd, "edit", designTask.Id, createFlag(BLOCKED_BY), buildTask.Id)
+			assert.ErrorIs(err, task.ErrCycle)
+		})
+
+		It("rejects a task blocking itself", func() {
+			_, err := executeCommand(rootCmd, "edit", designTask.Id, createFlag(BLOCKED_BY), designTask.Id)
+			assert.Error(err)
+		})
+
+		It("removes a dependency", func() {
+			editedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "edit", buildTask.Id, createFlag(UNBLOCK), designTask.Id),
+			)
+			assert.NoError(err)
+			assert.Empty(editedTask.BlockedBy)
+		})
+
+		It("lists unblocked tasks by priority and due date", func() {
+			assert.Equal(
+				[]string{"Ship", "Design"},
+				extractTitles(executeCommand(rootCmd, "next")),
+			)
+		})
+
+		It("unblocks a task once its blockers are complete", func() {
+			_, err := executeCommand(rootCmd, "edit", designTask.Id, createFlag(COMPLETE), "true")
+			assert.NoError(err)
+
+			resetCommands()
+
+			assert.Equal(
+				[]string{"Build", "Ship"},
+				extractTitles(executeCommand(rootCmd, "next")),
+			)
+		})
+
+		It("marks blocked tasks and names their blockers in the list", func() {
+			output, err := executeCommand(rootCmd, "list")
+			assert.NoError(err)
+
+			var tasks []mockListedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			blockedTask, found := lo.Find(tasks, func(item mockListedTask) bool { return item.Blocked })
+			assert.True(found)
+			assert.Equal(buildTask.Id, blockedTask.Id)
+			assert.Equal("Design", blockedTask.Blockers[0].Title)
+		})
+	})
+
+	Context("Recurring tasks", func() {
+		var choreTask mockPersistedTask
+		var recurringPath string
+
+		BeforeEach(func() {
+			recurringPath = useStorage("recurring.json")
+
+			var err error
+
+			choreTask, err = getMockPersistedTaskBasedOnOutput(
+				executeCommand(
+					rootCmd, "add", "Water plants", createFlag(REPEAT), "weekly on mon/wed",
+					createFlag(DUE), "2999-01-01", createFlag(TAG), "home",
+				),
+			)
+			assert.NoError(err)
+
+			resetCommands()
+
+			_, err = executeCommand(rootCmd, "add", "One off")
+			assert.NoError(err)
+
+			resetCommands()
+		})
+
+		It("stores the rule", func() {
+			assert.Equal("FREQ=WEEKLY;BYDAY=MO,WE", choreTask.Repeat)
+		})
+
+		It("rejects rules it doesn't understand", func() {
+			_, err := executeCommand(rootCmd, "add", "Sometimes", createFlag(REPEAT), "now and then")
+			assert.Error(err)
+		})
+
+		It("creates the next instance when a recurring task is completed", func() {
+			completedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "edit", choreTask.Id, createFlag(COMPLETE), "true"),
+			)
+			assert.NoError(err)
+			assert.Empty(completedTask.Repeat)
+
+			data, err := os.ReadFile(recurringPath)
+			assert.NoError(err)
+
+			mockTasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+			assert.Len(mockTasks, 3)
+
+			nextTask := mockTasks[0]
+			assert.NotEqual(choreTask.Id, nextTask.Id)
+			assert.Equal("Water plants", nextTask.Title)
+			assert.False(nextTask.Complete)
+			assert.Equal(choreTask.Repeat, nextTask.Repeat)
+			assert.Equal([]string{"home"}, nextTask.Tags)
+			// January 1st 2999 is a Tuesday so the next one is on Wednesday.
+			assert.Equal("2999-01-02", time.UnixMicro(nextTask.Due).Format(time.DateOnly))
+		})
+
+		It("lists only the recurring tasks", func() {
+			output, err := executeCommand(rootCmd, "list", createFlag(RECURRING))
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+			assert.Equal([]string{"Water plants"}, lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title }))
+		})
+
+		It("stops repeating", func() {
+			editedTask, err := getMockPersistedTaskBasedOnOutput(
+				executeCommand(rootCmd, "edit", choreTask.Id, createFlag(REPEAT), "none"),
+			)
+			assert.NoError(err)
+			assert.Empty(editedTask.Repeat)
+		})
+	})
+
+	Context("Lists", func() {
+		var groceriesTask mockPersistedTask
+
+		type mockListGroup struct {
+			List  string              `json:"list"`
+			Tasks []mockPersistedTask `json:"tasks"`
+		}
+
+		extractTitles := func(output string, err error) []string {
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			return lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title })
+		}
+
+		BeforeEach(func() {
+			useStorage("lists.json")
+
+			_, err := run("lists", "create", "work")
+			assert.NoError(err)
+
+			_, err = run("add", "Report", createFlag(LIST), "work")
+			assert.NoError(err)
+
+			rootCmd.PersistentFlags().Set(LIST, "")
+
+			groceriesTask, err = getMockPersistedTaskBasedOnOutput(run("add", "Groceries"))
+			assert.NoError(err)
+		})
+
+		AfterEach(func() {
+			rootCmd.PersistentFlags().Set(LIST, "")
+		})
+
+		It("puts tasks in the default list", func() {
+			assert.Equal(task.DEFAULT_LIST, groceriesTask.List)
+			assert.Equal([]string{"Groceries"}, extractTitles(run("list")))
+		})
+
+		It("lists the tasks in the list passed to --list", func() {
+			assert.Equal([]string{"Report"}, extractTitles(run("list", createFlag(LIST), "work")))
+		})
+
+		It("rejects a list that doesn't exist", func() {
+			_, err := run("add", "Lost", createFlag(LIST), "missing")
+			assert.ErrorIs(err, task.ErrListNotFound)
+		})
+
+		It("switches the active list", func() {
+			_, err := run("lists", "use", "work")
+			assert.NoError(err)
+
+			output, err := run("lists", "show")
+			assert.NoError(err)
+			assert.Equal("work", strings.TrimSpace(output))
+
+			assert.Equal([]string{"Report"}, extractTitles(run("list")))
+		})
+
+		It("renames a list along with its tasks", func() {
+			_, err := run("lists", "rename", "work", "office")
+			assert.NoError(err)
+
+			assert.Equal([]string{"Report"}, extractTitles(run("list", createFlag(LIST), "office")))
+		})
+
+		It("refuses to delete a list with tasks unless forced", func() {
+			_, err := run("lists", "delete", "work")
+			assert.Error(err)
+
+			_, err = run("lists", "delete", "work", createFlag(FORCE))
+			assert.NoError(err)
+
+			output, err := run("list", createFlag(ALL_LISTS))
+			assert.NoError(err)
+			assert.NotContains(output, "Report")
+		})
+
+		It("moves a task to another list", func() {
+			movedTask, err := getMockPersistedTaskBasedOnOutput(run("move", groceriesTask.Id, createFlag(TO), "work"))
+			assert.NoError(err)
+			assert.Equal("work", movedTask.List)
+
+			assert.ElementsMatch([]string{"Report", "Groceries"}, extractTitles(run("list", createFlag(LIST), "work")))
+		})
+
+		It("moves subtasks with their parent and not on their own", func() {
+			subtask, err := getMockPersistedTaskBasedOnOutput(run("add", "Milk", createFlag(PARENT), groceriesTask.Id))
+			assert.NoError(err)
+
+			_, err = run("move", subtask.Id, createFlag(TO), "work")
+			assert.ErrorContains(err, "is a subtask")
+
+			_, err = run("move", groceriesTask.Id, createFlag(TO), "work")
+			assert.NoError(err)
+
+			assert.ElementsMatch([]string{"Report", "Groceries", "Milk"}, extractTitles(run("list", createFlag(LIST), "work")))
+		})
+
+		It("groups tasks by list", func() {
+			output, err := run("list", createFlag(ALL_LISTS))
+			assert.NoError(err)
+
+			var groups []mockListGroup
+			assert.NoError(json.Unmarshal([]byte(output), &groups))
+
+			assert.Equal(
+				[]string{task.DEFAULT_LIST, "work"},
+				lo.Map(groups, func(group mockListGroup, index int) string { return group.List }),
+			)
+			assert.Equal("Report", groups[1].Tasks[0].Title)
+		})
+	})
+
+	Context("Short ids", func() {
+		var shortIdsPath string
+
+		readTasks := func() []mockPersistedTask {
+			data, err := os.ReadFile(shortIdsPath)
+			assert.NoError(err)
+
+			tasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+			return tasks
+		}
+
+		// The ids share their first characters so prefixes can be ambiguous.
+		BeforeEach(func() {
+			shortIdsPath = useStorage("short-ids.json")
+
+			data, err := json.Marshal([]mockPersistedTask{
+				{Id: "abcd1111", Title: "First", Priority: task.LOW.Value(), CreatedAt: 1},
+				{Id: "abcd2222", Title: "Second", Priority: task.LOW.Value(), CreatedAt: 2},
+				{Id: "ef001111", Title: "Third", Priority: task.LOW.Value(), CreatedAt: 3},
+			})
+			assert.NoError(err)
+			assert.NoError(os.WriteFile(shortIdsPath, data, 0o600))
+		})
+
+		It("numbers tasks in the order they were created", func() {
+			output, err := run("list")
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			numbers := lo.SliceToMap(tasks, func(item mockPersistedTask) (string, int) { return item.Title, item.Number })
+			assert.Equal(map[string]int{"First": 1, "Second": 2, "Third": 3}, numbers)
+		})
+
+		It("shows the shortest prefix that's unique", func() {
+			output, err := run("list")
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+
+			shortIds := lo.SliceToMap(tasks, func(item mockPersistedTask) (string, string) { return item.Id, item.ShortId })
+			assert.Equal(map[string]string{"abcd1111": "abcd1", "abcd2222": "abcd2", "ef001111": "ef00"}, shortIds)
+		})
+
+		It("edits a task by the start of its id", func() {
+			editedTask, err := getMockPersistedTaskBasedOnOutput(run("edit", "ef0", createFlag(TITLE), "Renamed"))
+			assert.NoError(err)
+			assert.Equal("ef001111", editedTask.Id)
+			assert.Equal("Renamed", editedTask.Title)
+		})
+
+		It("lists the candidates when a prefix is ambiguous", func() {
+			_, err := run("edit", "abcd", createFlag(TITLE), "Renamed")
+			assert.ErrorIs(err, task.ErrAmbiguousId)
+			assert.ErrorContains(err, "abcd1111 First")
+			assert.ErrorContains(err, "abcd2222 Second")
+
+			_, err = run("delete", "abcd")
+			assert.ErrorIs(err, task.ErrAmbiguousId)
+			assert.Len(readTasks(), 3)
+		})
+
+		It("deletes a task by its number and keeps the other numbers", func() {
+			_, err := run("delete", "#1")
+			assert.NoError(err)
+
+			_, err = run("add", "Fourth")
+			assert.NoError(err)
+
+			numbers := lo.SliceToMap(readTasks(), func(item mockPersistedTask) (string, int) { return item.Title, item.Number })
+			assert.Equal(map[string]int{"Second": 2, "Third": 3, "Fourth": 4}, numbers)
+		})
+
+		It("doesn't give the number of a deleted task to the next one", func() {
+			_, err := run("delete", "#3")
+			assert.NoError(err)
+
+			_, err = run("lists", "create", "work")
+			assert.NoError(err)
+
+			addedTask, err := getMockPersistedTaskBasedOnOutput(run("add", "Fourth"))
+			assert.NoError(err)
+			assert.Equal(4, addedTask.Number)
+
+			_, err = run("delete", "#4")
+			assert.NoError(err)
+
+			addedTask, err = getMockPersistedTaskBasedOnOutput(run("add", "Fifth"))
+			assert.NoError(err)
+			assert.Equal(5, addedTask.Number)
+		})
+
+		It("gives a moved task the next number in its new list", func() {
+			_, err := run("lists", "create", "work")
+			assert.NoError(err)
+
+			movedTask, err := getMockPersistedTaskBasedOnOutput(run("move", "2", createFlag(TO), "work"))
+			assert.NoError(err)
+			assert.Equal("abcd2222", movedTask.Id)
+			assert.Equal(1, movedTask.Number)
+		})
+	})
+
+	Context("Table output", func() {
+		runStyled := func(args ...string) (string, error) {
+			return run(append([]string{"--plain=false"}, args...)...)
+		}
+
+		BeforeEach(func() {
+			tablePath := useStorage("table.json")
+			GinkgoT().Setenv("COLUMNS", "80")
+
+			now := time.Now()
+
+			data, err := json.Marshal([]mockPersistedTask{
+				{
+					Id:        "abcd1111",
+					Title:     "Write the quarterly report that summarises every project the team shipped",
+					Priority:  task.HIGH.Value(),
+					CreatedAt: now.Add(-3 * time.Hour).UnixMicro(),
+					UpdatedAt: now.Add(-5 * time.Minute).UnixMicro(),
+				},
+				{
+					Id:        "ef001111",
+					Title:     "Water plants",
+					Priority:  task.LOW.Value(),
+					Complete:  true,
+					CreatedAt: now.Add(-50 * time.Hour).UnixMicro(),
+					UpdatedAt: now.UnixMicro(),
+				},
+			})
+			assert.NoError(err)
+			assert.NoError(os.WriteFile(tablePath, data, 0o600))
+		})
+
+		It("shows the short id, priority, status and relative times in a table", func() {
+			output, err := runStyled("list")
+			assert.NoError(err)
+
+			for _, text := range []string{"ID", "TITLE", "PRIORITY", "STATUS", "CREATED", "UPDATED", "abcd", "HIGH", "done", "todo", "3h ago", "2d ago", "5m ago"} {
+				assert.Contains(output, text)
+			}
+		})
+
+		It("wraps the table to fit the terminal", func() {
+			output, err := runStyled("list")
+			assert.NoError(err)
+
+			for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+				assert.LessOrEqual(len([]rune(stripAnsi(line))), 80)
+			}
+
+			assert.Contains(output, "quarterly")
+		})
+
+		It("shows the columns passed to --columns in order", func() {
+			output, err := runStyled("list", createFlag(COLUMNS), "title,due")
+			assert.NoError(err)
+
+			header := strings.Split(output, "\n")[1]
+			assert.Less(strings.Index(header, "TITLE"), strings.Index(header, "DUE"))
+			assert.NotContains(output, "PRIORITY")
+		})
+
+		It("rejects a column it doesn't know", func() {
+			_, err := runStyled("list", createFlag(COLUMNS), "title,colour")
+			assert.ErrorContains(err, "colour")
+		})
+
+		It("prints JSON with --output json", func() {
+			output, err := runStyled("list", createFlag(OUTPUT), "json")
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+			assert.Len(tasks, 2)
+		})
+	})
+
+	Context("Output formats", func() {
+		BeforeEach(func() {
+			useStorage("output.json")
+
+			_, err := run("add", "First", createFlag(PRIORITY), "high")
+			assert.NoError(err)
+
+			_, err = run("add", "Second")
+			assert.NoError(err)
+		})
+
+		It("prints a line of JSON for every task with ndjson", func() {
+			output, err := run("list", createFlag(OUTPUT), NDJSON, createFlag(SORT_DATE), EARLIEST)
+			assert.NoError(err)
+
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			assert.Len(lines, 2)
+
+			var first mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(lines[0]), &first))
+			assert.Equal("First", first.Title)
+		})
+
+		It("prints YAML", func() {
+			output, err := run("add", "Third", createFlag(OUTPUT), YAML)
+			assert.NoError(err)
+			assert.Contains(output, "title: Third\n")
+			assert.Contains(output, "priority: low\n")
+		})
+
+		It("prints CSV with a header", func() {
+			output, err := run("list", createFlag(OUTPUT), task.CSV)
+			assert.NoError(err)
+
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			assert.Len(lines, 3)
+			assert.True(strings.HasPrefix(lines[0], "id,title,"))
+		})
+
+		It("executes the template for every task", func() {
+			output, err := run("list", createFlag(TEMPLATE), "{{.Title}} ({{.Priority}})", createFlag(SORT_DATE), EARLIEST)
+			assert.NoError(err)
+			assert.Equal("First (high)\nSecond (low)\n", output)
+		})
+
+		It("rejects a template that doesn't parse before changing anything", func() {
+			_, err := run("add", "Broken", createFlag(TEMPLATE), "{{.Title")
+			assert.Error(err)
+
+			output, err := run("list", createFlag(OUTPUT), NDJSON)
+			assert.NoError(err)
+			assert.NotContains(output, "Broken")
+		})
+
+		It("reports the ids of the deleted tasks", func() {
+			output, err := run("delete", "#1", createFlag(OUTPUT), JSON)
+			assert.NoError(err)
+
+			var deleted []struct {
+				Id    string `json:"id"`
+				Title string `json:"title"`
+			}
+			assert.NoError(json.Unmarshal([]byte(output), &deleted))
+			assert.Len(deleted, 1)
+			assert.Equal("First", deleted[0].Title)
+			assert.NotEmpty(deleted[0].Id)
+		})
+
+		It("prints an empty list when nothing matches", func() {
+			output, err := run("list", createFlag(OUTPUT), JSON, createFlag(FILTER_COMPLETE))
+			assert.NoError(err)
+			assert.Equal("[]", strings.TrimSpace(output))
+		})
+
+		It("rejects --output and --template on commands that don't print tasks", func() {
+			_, err := run("search", "First", createFlag(OUTPUT), JSON)
+			assert.ErrorIs(err, custom_errors.InvalidFlag)
+			assert.ErrorContains(err, "only works with add, edit, delete, list and next")
+
+			_, err = run("lists", "delete", "work", createFlag(TEMPLATE), "{{.Title}}")
+			assert.ErrorIs(err, custom_errors.InvalidFlag)
+		})
+	})
+
+	Context("Task browser", func() {
+		// runCommand feeds what a command sends back into the model like a program would.
+		// Commands that wait like cursor blinks are dropped.
+		var runCommand func(model tea.Model, command tea.Cmd, depth int) tea.Model
+
+		runCommand = func(model tea.Model, command tea.Cmd, depth int) tea.Model {
+			if command == nil || depth > 20 {
+				return model
+			}
+
+			messages := make(chan tea.Msg, 1)
+			go func() { messages <- command() }()
+
+			select {
+			case msg := <-messages:
+				if batch, ok := msg.(tea.BatchMsg); ok {
+					for _, batched := range batch {
+						model = runCommand(model, batched, depth+1)
+					}
+					return model
+				}
+
+				if msg == nil {
+					return model
+				}
+
+				model, command = model.Update(msg)
+				return runCommand(model, command, depth+1)
+			case <-time.After(50 * time.Millisecond):
+				return model
+			}
+		}
+
+		press := func(model tea.Model, keys ...string) tea.Model {
+			for _, pressed := range keys {
+				msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(pressed)}
+
+				switch pressed {
+				case " ":
+					msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(pressed)}
+				case "enter":
+					msg = tea.KeyMsg{Type: tea.KeyEnter}
+				case "esc":
+					msg = tea.KeyMsg{Type: tea.KeyEsc}
+				}
+
+				var command tea.Cmd
+				model, command = model.Update(msg)
+				model = runCommand(model, command, 0)
+			}
+
+			return model
+		}
+
+		openBrowser := func() tea.Model {
+			resetCommands()
+
+			tuiCmd := CreateTUICommand()
+			rootCmd.AddCommand(tuiCmd)
+			assert.NoError(tuiCmd.ParseFlags(nil))
+
+			model, err := NewTaskBrowser(tuiCmd)
+			assert.NoError(err)
+
+			return model
+		}
+
+		var browserPath string
+
+		findTask := func(title string) (mockPersistedTask, bool) {
+			data, err := os.ReadFile(browserPath)
+			assert.NoError(err)
+
+			tasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+
+			return lo.Find(tasks, func(item mockPersistedTask) bool { return item.Title == title })
+		}
+
+		BeforeEach(func() {
+			browserPath = useStorage("browser.json")
+
+			data, err := json.Marshal([]mockPersistedTask{
+				{Id: "abcd1111", Title: "Newest", Description: "Pick up the parcel", Priority: task.LOW.Value(), CreatedAt: 3},
+				{Id: "abcd2222", Title: "Middle", Priority: task.HIGH.Value(), CreatedAt: 2},
+				{Id: "ef001111", Title: "Oldest", Priority: task.MEDIUM.Value(), CreatedAt: 1},
+			})
+			assert.NoError(err)
+			assert.NoError(os.WriteFile(browserPath, data, 0o600))
+		})
+
+		It("completes the selected task with space", func() {
+			press(openBrowser(), "j", " ")
+
+			middle, ok := findTask("Middle")
+			assert.True(ok)
+			assert.True(middle.Complete)
+
+			newest, _ := findTask("Newest")
+			assert.False(newest.Complete)
+		})
+
+		It("filters the tasks as you type", func() {
+			view := press(openBrowser(), "/", "p", "a", "r", "c", "e", "l", "enter").View()
+
+			assert.Contains(view, "Newest")
+			assert.NotContains(view, "Oldest")
+		})
+
+		It("cycles the sort and moves to the last task", func() {
+			model := press(openBrowser(), "s", "G", " ")
+			assert.Contains(model.View(), "sorted by priority")
+
+			newest, _ := findTask("Newest")
+			assert.True(newest.Complete)
+		})
+
+		It("deletes a task once it's confirmed", func() {
+			model := press(openBrowser(), "G", "d", "n")
+
+			_, ok := findTask("Oldest")
+			assert.True(ok)
+
+			press(model, "d", "y")
+
+			_, ok = findTask("Oldest")
+			assert.False(ok)
+		})
+
+		It("keeps a task someone changed while the delete was being confirmed", func() {
+			model := press(openBrowser(), "G", "d")
+
+			_, err := run("edit", "ef001111", createFlag(TITLE), "Oldest renamed")
+			assert.NoError(err)
+
+			press(model, "y")
+
+			_, ok := findTask("Oldest renamed")
+			assert.True(ok)
+		})
+
+		It("saves the changes made in the edit form", func() {
+			model := press(openBrowser(), "e")
+			assert.Contains(model.View(), "Title")
+
+			press(model, "!", "enter", "enter", "enter", "enter", "enter")
+
+			edited, ok := findTask("Newest!")
+			assert.True(ok)
+			assert.Equal("Pick up the parcel", edited.Description)
+		})
+	})
+
+	Context("Statuses", func() {
+		var statusesPath string
+
+		runTask := func(args ...string) (mockPersistedTask, error) {
+			return getMockPersistedTaskBasedOnOutput(run(args...))
+		}
+
+		setStatuses := func(statuses ...string) {
+			configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+			GinkgoT().Setenv(config.CONFIG_PATH_ENV, configPath)
+
+			data, err := json.Marshal(map[string][]string{"statuses": statuses})
+			assert.NoError(err)
+			assert.NoError(os.WriteFile(configPath, data, 0o600))
+		}
+
+		BeforeEach(func() {
+			statusesPath = useStorage("statuses.json")
+			setStatuses()
+		})
+
+		It("starts tasks as todo and moves them with --status", func() {
+			added, err := runTask("add", "Write the changelog")
+			assert.NoError(err)
+			assert.Equal("todo", added.Status)
+
+			edited, err := runTask("edit", added.Id, createFlag(STATUS), "doing")
+			assert.NoError(err)
+			assert.Equal("doing", edited.Status)
+			assert.False(edited.Complete)
+
+			edited, err = runTask("edit", added.Id, createFlag(STATUS), "done")
+			assert.NoError(err)
+			assert.True(edited.Complete)
+		})
+
+		It("keeps --complete as a shortcut for done and back to todo", func() {
+			added, err := runTask("add", "Write the changelog", createFlag(STATUS), "blocked")
+			assert.NoError(err)
+			assert.Equal("blocked", added.Status)
+
+			edited, err := runTask("edit", added.Id, createFlag(COMPLETE), "false")
+			assert.NoError(err)
+			assert.Equal("blocked", edited.Status)
+
+			edited, err = runTask("edit", added.Id, createFlag(COMPLETE), "true")
+			assert.NoError(err)
+			assert.Equal("done", edited.Status)
+
+			edited, err = runTask("edit", added.Id, createFlag(COMPLETE), "false")
+			assert.NoError(err)
+			assert.Equal("todo", edited.Status)
+
+			_, err = runTask("edit", added.Id, createFlag(COMPLETE), "true", createFlag(STATUS), "done")
+			assert.Error(err)
+		})
+
+		It("accepts the extra statuses from the config", func() {
+			added, err := runTask("add", "Write the changelog")
+			assert.NoError(err)
+
+			_, err = runTask("edit", added.Id, createFlag(STATUS), "review")
+			assert.Error(err)
+
+			setStatuses("review")
+
+			edited, err := runTask("edit", added.Id, createFlag(STATUS), "review")
+			assert.NoError(err)
+			assert.Equal("review", edited.Status)
+		})
+
+		It("finds tasks by status with --where", func() {
+			_, err := runTask("add", "Write the changelog", createFlag(STATUS), "doing")
+			assert.NoError(err)
+
+			_, err = runTask("add", "Tag the release")
+			assert.NoError(err)
+
+			resetCommands()
+
+			output, err := executeCommand(rootCmd, "list", createFlag(WHERE), "status = doing")
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+			assert.Equal([]string{"Write the changelog"}, lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title }))
+		})
+
+		Context("Board", func() {
+			press := func(model tea.Model, keys ...string) tea.Model {
+				for _, pressed := range keys {
+					model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(pressed)})
+				}
+
+				return model
+			}
+
+			openBoard := func() tea.Model {
+				resetCommands()
+
+				boardCmd := CreateBoardCommand()
+				rootCmd.AddCommand(boardCmd)
+				assert.NoError(boardCmd.ParseFlags(nil))
+
+				model, err := NewTaskBoard(boardCmd)
+				assert.NoError(err)
+
+				return model
+			}
+
+			findStatus := func(title string) string {
+				data, err := os.ReadFile(statusesPath)
+				assert.NoError(err)
+
+				tasks, err := unmarshalMockPersistedTasks(data)
+				assert.NoError(err)
+
+				item, _ := lo.Find(tasks, func(item mockPersistedTask) bool { return item.Title == title })
+				return item.Status
+			}
+
+			BeforeEach(func() {
+				data, err := json.Marshal([]mockPersistedTask{
+					{Id: "abcd1111", Title: "Write the changelog", Priority: task.HIGH.Value(), Status: "todo", CreatedAt: 1},
+					{Id: "abcd2222", Title: "Tag the release", Priority: task.LOW.Value(), Status: "todo", CreatedAt: 2},
+				})
+				assert.NoError(err)
+				assert.NoError(os.WriteFile(statusesPath, data, 0o600))
+			})
+
+			It("shows a column for every status", func() {
+				setStatuses("review")
+
+				view := stripAnsi(openBoard().View())
+
+				for _, column := range []string{"TODO 2", "DOING 0", "BLOCKED 0", "REVIEW 0", "DONE 0"} {
+					assert.Contains(view, column)
+				}
+
+				assert.Less(strings.Index(view, "REVIEW"), strings.Index(view, "DONE"))
+			})
+
+			It("moves the selected task on with l and back with h", func() {
+				model := press(openBoard(), "l", "l")
+
+				assert.Equal("blocked", findStatus("Write the changelog"))
+				assert.Equal("todo", findStatus("Tag the release"))
+
+				press(model, "h")
+
+				assert.Equal("doing", findStatus("Write the changelog"))
+			})
+
+			It("completes a task moved to done", func() {
+				press(openBoard(), "j", "l", "l", "l", "l")
+
+				assert.Equal("done", findStatus("Tag the release"))
+				assert.Equal("todo", findStatus("Write the changelog"))
+			})
+		})
+	})
+
+	Context("Queries", func() {
+		var queriesPath string
+
+		readTitles := func() []string {
+			data, err := os.ReadFile(queriesPath)
+			assert.NoError(err)
+
+			tasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+
+			return lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title })
+		}
+
+		BeforeEach(func() {
+			queriesPath = useStorage("queries.json")
+
+			for _, args := range [][]string{
+				{"add", "Deploy the API", createFlag(PRIORITY), "high"},
+				{"add", "Write the report", createFlag(PRIORITY), "medium"},
+				{"add", "Buy groceries"},
+			} {
+				_, err := run(args...)
+				assert.NoError(err)
+			}
+		})
+
+		// --where sticks to the commands so later specs get fresh ones.
+		AfterEach(func() {
+			resetCommands()
+		})
+
+		It("lists the tasks a query matches", func() {
+			output, err := run("list", createFlag(WHERE), `priority >= medium and (title ~ "deploy" or created > -7d)`)
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+			assert.ElementsMatch(
+				[]string{"Deploy the API", "Write the report"},
+				lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title }),
+			)
+		})
+
+		It("sorts and filters by priority at the same time", func() {
+			_, err := run("list", createFlag(FILTER_PRIORITY), "low", createFlag(SORT_PRIORITY), HIGHEST)
+			assert.NoError(err)
+		})
+
+		It("points at the column where a query went wrong", func() {
+			_, err := run("list", createFlag(WHERE), "priority >= urgent")
+			assert.ErrorIs(err, query.ErrSyntax)
+			assert.ErrorContains(err, "at column 13")
+		})
+
+		It("edits every task a query matches", func() {
+			output, err := run("edit", createFlag(WHERE), "priority < high", createFlag(COMPLETE), "true")
+			assert.NoError(err)
+
+			var tasks []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &tasks))
+			assert.Len(tasks, 2)
+			assert.True(lo.EveryBy(tasks, func(item mockPersistedTask) bool { return item.Complete }))
+		})
+
+		It("needs a change when editing with a query", func() {
+			_, err := run("edit", createFlag(WHERE), "complete")
+			assert.Error(err)
+		})
+
+		It("deletes every task a query matches", func() {
+			_, err := run("delete", createFlag(WHERE), "not priority = high")
+			assert.NoError(err)
+			assert.Equal([]string{"Deploy the API"}, readTitles())
+
+			_, err = run("delete", createFlag(WHERE), "title ~ nothing")
+			assert.Error(err)
+		})
+	})
+
+	Context("Search", func() {
+		type mockSearchResult struct {
+			mockPersistedTask
+			Score   float64     `json:"score"`
+			Matches []task.Span `json:"matches"`
+		}
+
+		search := func(terms ...string) []mockSearchResult {
+			output, err := executeCommand(rootCmd, append([]string{"search"}, terms...)...)
+			assert.NoError(err)
+
+			var results []mockSearchResult
+			assert.NoError(json.Unmarshal([]byte(output), &results))
+
+			return results
+		}
+
+		titles := func(results []mockSearchResult) []string {
+			return lo.Map(results, func(item mockSearchResult, index int) string { return item.Title })
+		}
+
+		BeforeEach(func() {
+			useStorage("search.json")
+
+			for _, args := range [][]string{
+				{"add", "Deploy the API", "Roll out the new release"},
+				{"add", "Write the release notes", "Everything that changed since the last deploy"},
+				{"add", "Buy groceries", "Milk and bread"},
+			} {
+				resetCommands()
+				_, err := executeCommand(rootCmd, args...)
+				assert.NoError(err)
+			}
+		})
+
+		It("ranks a match in the title above one in the description", func() {
+			results := search("deploy")
+			assert.Equal([]string{"Deploy the API", "Write the release notes"}, titles(results))
+			assert.Greater(results[0].Score, results[1].Score)
+			assert.Equal(task.Span{Field: task.TITLE_FIELD, Start: 0, End: 6}, results[0].Matches[0])
+			assert.Equal(task.DESCRIPTION_FIELD, results[1].Matches[0].Field)
+		})
+
+		It("tolerates typos and skipped letters", func() {
+			assert.Equal([]string{"Deploy the API", "Write the release notes"}, titles(search("deplyo")))
+			assert.Equal([]string{"Buy groceries"}, titles(search("grcries")))
+		})
+
+		It("needs every term to match", func() {
+			assert.Equal([]string{"Write the release notes"}, titles(search("release", "notes")))
+		})
+
+		It("says when nothing matches", func() {
+			output, err := executeCommand(rootCmd, "search", "zebra")
+			assert.NoError(err)
+			assert.JSONEq("[]", output)
+
+			resetCommands()
+
+			output, err = executeCommand(rootCmd, "search", "zebra", "--plain=false")
+			assert.NoError(err)
+			assert.Equal("There are no tasks that match these terms\n", output)
+		})
+	})
+
+	Context("Undo", func() {
+		var undoPath string
+
+		type mockOperation struct {
+			Command string   `json:"command"`
+			Undone  bool     `json:"undone"`
+			Tasks   []string `json:"tasks"`
+		}
+
+		readTitles := func() []string {
+			data, err := os.ReadFile(undoPath)
+			assert.NoError(err)
+
+			tasks, err := unmarshalMockPersistedTasks(data)
+			assert.NoError(err)
+
+			return lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title })
+		}
+
+		BeforeEach(func() {
+			undoPath = useStorage("undo.json")
+
+			for _, args := range [][]string{
+				{"add", "Water the plants"},
+				{"add", "Call the bank"},
+				{"add", "Ship the release", createFlag(PRIORITY), "high"},
+			} {
+				_, err := run(args...)
+				assert.NoError(err)
+			}
+		})
+
+		AfterEach(func() {
+			resetCommands()
+		})
+
+		It("brings back the tasks a delete removed", func() {
+			_, err := run("delete", "low", createFlag(PRIORITY))
+			assert.NoError(err)
+			assert.Equal([]string{"Ship the release"}, readTitles())
+
+			output, err := run("undo")
+			assert.NoError(err)
+
+			var operations []mockOperation
+			assert.NoError(json.Unmarshal([]byte(output), &operations))
+			assert.Equal("delete low --priority", operations[0].Command)
+			assert.Len(operations[0].Tasks, 2)
+
+			assert.ElementsMatch([]string{"Water the plants", "Call the bank", "Ship the release"}, readTitles())
+		})
+
+		It("redoes what was undone", func() {
+			_, err := run("delete", "low", createFlag(PRIORITY))
+			assert.NoError(err)
+
+			_, err = run("undo")
+			assert.NoError(err)
+
+			_, err = run("redo")
+			assert.NoError(err)
+			assert.Equal([]string{"Ship the release"}, readTitles())
+
+			_, err = run("redo")
+			assert.ErrorIs(err, task.ErrNothingToRedo)
+		})
+
+		It("undoes more than one command and edits of the same task in a row", func() {
+			tasks, err := unmarshalMockPersistedTasks(lo.Must(os.ReadFile(undoPath)))
+			assert.NoError(err)
+
+			_, err = run("edit", tasks[0].Id, createFlag(TITLE), "Ship it")
+			assert.NoError(err)
+
+			_, err = run("undo", "2")
+			assert.NoError(err)
+			assert.ElementsMatch([]string{"Water the plants", "Call the bank"}, readTitles())
+		})
+
+		It("can't redo after something else changed", func() {
+			_, err := run("undo")
+			assert.NoError(err)
+
+			_, err = run("add", "Something new")
+			assert.NoError(err)
+
+			_, err = run("redo")
+			assert.ErrorIs(err, task.ErrNothingToRedo)
+		})
+
+		It("leaves a task that changed since alone unless forced", func() {
+			tasks, err := unmarshalMockPersistedTasks(lo.Must(os.ReadFile(undoPath)))
+			assert.NoError(err)
+
+			_, err = run("edit", tasks[0].Id, createFlag(TITLE), "Ship it")
+			assert.NoError(err)
+
+			// Someone changes the file by hand so the journal doesn't know about it.
+			data := lo.Must(os.ReadFile(undoPath))
+			assert.NoError(os.WriteFile(undoPath, bytes.ReplaceAll(data, []byte("Ship it"), []byte("Ship it now")), 0o600))
+
+			_, err = run("undo")
+			assert.ErrorIs(err, task.ErrChangedSince)
+			assert.Contains(readTitles(), "Ship it now")
+
+			_, err = run("undo", createFlag(FORCE))
+			assert.NoError(err)
+		})
+
+		It("logs the commands most recent first", func() {
+			_, err := run("undo")
+			assert.NoError(err)
+
+			output, err := run("log")
+			assert.NoError(err)
+
+			var operations []mockOperation
+			assert.NoError(json.Unmarshal([]byte(output), &operations))
+			assert.Equal(
+				[]string{`add "Ship the release" --priority high`, `add "Call the bank"`, `add "Water the plants"`},
+				lo.Map(operations, func(item mockOperation, index int) string { return item.Command }),
+			)
+			assert.Equal([]bool{true, false, false}, lo.Map(operations, func(item mockOperation, index int) bool { return item.Undone }))
+		})
+	})
+
+	Context("History", func() {
+		type mockFieldChange struct {
+			Field string `json:"field"`
+			Old   string `json:"old"`
+			New   string `json:"new"`
+		}
+
+		type mockHistoryEntry struct {
+			Command string            `json:"command"`
+			Kind    string            `json:"kind"`
+			Changes []mockFieldChange `json:"changes"`
+		}
+
+		history := func(reference string) []mockHistoryEntry {
+			output, err := run("history", reference)
+			assert.NoError(err)
+
+			var entries []mockHistoryEntry
+			assert.NoError(json.Unmarshal([]byte(output), &entries))
+
+			return entries
+		}
+
+		BeforeEach(func() {
+			useStorage("history.json")
+
+			_, err := run("add", "Water the plants")
+			assert.NoError(err)
+		})
+
+		AfterEach(func() {
+			resetCommands()
+		})
+
+		It("records the old and new value of every field a command changed", func() {
+			_, err := run("edit", "1", createFlag(PRIORITY), "high", createFlag(TITLE), "Water the garden")
+			assert.NoError(err)
+
+			entries := history("1")
+			assert.Len(entries, 2)
+
+			assert.Equal(`add "Water the plants"`, entries[0].Command)
+			assert.Equal(task.ADDED, entries[0].Kind)
+			assert.Contains(entries[0].Changes, mockFieldChange{Field: "title", Old: "", New: "Water the plants"})
+
+			assert.Equal(task.EDITED, entries[1].Kind)
+			assert.Equal([]mockFieldChange{
+				{Field: "title", Old: "Water the plants", New: "Water the garden"},
+				{Field: "priority", Old: "low", New: "high"},
+			}, entries[1].Changes)
+		})
+
+		It("shows undoing and deleting even after the task is gone", func() {
+			_, err := run("edit", "1", createFlag(COMPLETE), "true")
+			assert.NoError(err)
+
+			_, err = run("undo")
+			assert.NoError(err)
+
+			_, err = run("delete", "1")
+			assert.NoError(err)
+
+			entries := history("1")
+			assert.Equal(
+				[]string{task.ADDED, task.EDITED, task.UNDONE, task.DELETED},
+				lo.Map(entries, func(entry mockHistoryEntry, index int) string { return entry.Kind }),
+			)
+			assert.Equal([]mockFieldChange{{Field: "status", Old: "done", New: "todo"}}, entries[2].Changes)
+		})
+
+		It("fails for a task that never existed", func() {
+			_, err := run("history", "42")
+			assert.ErrorContains(err, "wasn't found 42")
+		})
+	})
+
+	Context("Import and export", func() {
+		BeforeEach(func() {
+			useStorage("imported.json")
+		})
+
+		AfterEach(func() {
+			resetCommands()
+		})
+
+		It("imports a todo.txt file and exports the same file", func() {
+			output, err := run("import", "testdata/todo.txt", createFlag(FORMAT), task.TODOTXT)
+			assert.NoError(err)
+
+			var imported []mockPersistedTask
+			assert.NoError(json.Unmarshal([]byte(output), &imported))
+			assert.Len(imported, 12)
+			assert.True(lo.EveryBy(imported, func(item mockPersistedTask) bool { return item.Number > 0 }))
+
+			exported := filepath.Join(GinkgoT().TempDir(), "todo.txt")
+
+			_, err = run("export", createFlag(FORMAT), task.TODOTXT, createFlag(FILE), exported)
+			assert.NoError(err)
+			assert.Equal(string(lo.Must(os.ReadFile("testdata/todo.txt"))), string(lo.Must(os.ReadFile(exported))))
+		})
+
+		It("imports the valid rows of a CSV file and fails listing the others", func() {
+			output, err := run(
+				"import", "testdata/sprint.csv", createFlag(FORMAT), task.CSV,
+				createFlag(MAP), "Summary=title", createFlag(MAP), "Prio=priority", createFlag(MAP), "Due date=due",
+			)
+			assert.ErrorIs(err, task.ErrInvalidRows)
+			assert.ErrorContains(err, "2 of 4 rows")
+			assert.ErrorContains(err, "row 4 priority")
+			assert.ErrorContains(err, "row 5 title")
+			assert.Contains(output, "Deploy the API")
+
+			tasks, err := unmarshalMockPersistedTasks(lo.Must(os.ReadFile(os.Getenv(task.STORAGE_PATH_ENV))))
+			assert.NoError(err)
+			assert.ElementsMatch([]string{"Deploy the API", "Write the docs"}, lo.Map(tasks, func(item mockPersistedTask, index int) string { return item.Title }))
+		})
+
+		It("only takes the formats it knows", func() {
+			_, err := run("export", createFlag(FORMAT), "docx")
+			assert.ErrorContains(err, "format flag must be one of")
+		})
+	})
+
+	Context("Editing tasks", Ordered, func() {
+		var mockTask mockPersistedTask
+
+		// The tasks are read before every spec because an earlier spec
+		// may have edited the same random task.
+		BeforeEach(func() {
+			mockTasks, err := getMockPersistedTasks()
+			assert.NoError(err)
+			assert.NotEmpty(mockTasks)
+
 			storageTask, err := getRandomPersistedTask(mockTasks)
 			assert.NoError(err)
 			assert.NotEmpty(storageTask)
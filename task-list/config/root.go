@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const APP_NAME = "task-list"
+
+const CONFIG_FILE_NAME = "config.json"
+
+// CONFIG_PATH_ENV lets users point task-list at a config file
+// that lives somewhere other than the XDG config dir.
+const CONFIG_PATH_ENV = "TASK_LIST_CONFIG"
+
+type Config struct {
+	// Store is the default location of the task file.
+	// It is used when neither the --store flag nor TASK_LIST_FILE is set.
+	Store string `json:"store"`
+	// Backend picks how tasks are stored.
+	// It's one of json, jsonl or memory and defaults to json.
+	Backend string `json:"backend"`
+	// ParentCompletion decides what happens when a task with open subtasks is completed.
+	// It's one of free, block or cascade and defaults to free.
+	ParentCompletion string `json:"parentCompletion"`
+	// Statuses are extra workflow statuses like review.
+	// They go between blocked and done in the order they're listed.
+	Statuses []string `json:"statuses"`
+}
+
+func Path() (string, error) {
+
+	if path := os.Getenv(CONFIG_PATH_ENV); path != "" {
+		return path, nil
+	}
+
+	configDir, error := os.UserConfigDir()
+
+	if error != nil {
+		return "", error
+	}
+
+	return filepath.Join(configDir, APP_NAME, CONFIG_FILE_NAME), nil
+
+}
+
+// Load reads the config file.
+// A missing config file is not an error it just means every setting uses its default.
+func Load() (Config, error) {
+
+	var config Config
+
+	path, error := Path()
+
+	if error != nil {
+		return config, error
+	}
+
+	byte, error := os.ReadFile(path)
+
+	if errors.Is(error, os.ErrNotExist) {
+		return config, nil
+	}
+
+	if error != nil {
+		return config, error
+	}
+
+	if error := json.Unmarshal(byte, &config); error != nil {
+		return config, error
+	}
+
+	return config, nil
+
+}
@@ -0,0 +1,340 @@
+package dates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+var ErrUnrecognized = errors.New("isn't a date task-list understands")
+
+var ErrAmbiguous = errors.New("is ambiguous")
+
+// AmbiguousError is returned when an input could mean more than one date.
+// Suggestions are inputs that would mean one date.
+type AmbiguousError struct {
+	Input       string
+	Suggestions []string
+}
+
+func (self AmbiguousError) Error() string {
+
+	return fmt.Sprintf(
+		"%q %s try one of %s",
+		self.Input,
+		ErrAmbiguous,
+		strings.Join(self.Suggestions, ", "),
+	)
+}
+
+func (self AmbiguousError) Unwrap() error {
+
+	return ErrAmbiguous
+}
+
+// Clock lets tests decide what now is.
+type Clock interface {
+	Now() time.Time
+}
+
+type ClockFunc func() time.Time
+
+func (self ClockFunc) Now() time.Time {
+
+	return self()
+}
+
+var SystemClock = ClockFunc(time.Now)
+
+// Parser resolves dates like today, tomorrow 17:00, next fri, in 3 days, eow and ISO dates.
+// Inputs without a time of day resolve to midnight.
+type Parser struct {
+	clock    Clock
+	location *time.Location
+}
+
+func NewParser(clock Clock, location *time.Location) Parser {
+
+	return Parser{clock, location}
+}
+
+// DefaultParser uses the system clock and the local timezone.
+func DefaultParser() Parser {
+
+	return NewParser(SystemClock, time.Local)
+}
+
+func Parse(input string) (time.Time, error) {
+
+	return DefaultParser().Parse(input)
+}
+
+var isoLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWeekday understands full and short weekday names like monday, mon and thurs.
+func ParseWeekday(name string) (time.Weekday, bool) {
+
+	weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+
+	return weekday, ok
+}
+
+var units = map[string]string{
+	"m": "minute", "min": "minute", "mins": "minute", "minute": "minute", "minutes": "minute",
+	"h": "hour", "hr": "hour", "hrs": "hour", "hour": "hour", "hours": "hour",
+	"d": "day", "day": "day", "days": "day",
+	"w": "week", "wk": "week", "wks": "week", "week": "week", "weeks": "week",
+	"mo": "month", "month": "month", "months": "month",
+}
+
+var (
+	timeOfDayPattern = regexp.MustCompile(`^(?:(.*?)\s+)?(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	inPattern        = regexp.MustCompile(`^in\s+(\d+)\s*([a-z]+)$`)
+	offsetPattern    = regexp.MustCompile(`^([+-])(\d+)\s*([a-z]+)$`)
+	slashPattern     = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+	whitespace       = regexp.MustCompile(`\s+`)
+)
+
+func (self Parser) Parse(input string) (time.Time, error) {
+
+	trimmedInput := strings.TrimSpace(input)
+
+	for _, layout := range isoLayouts {
+
+		if parsedTime, error := time.ParseInLocation(layout, trimmedInput, self.location); error == nil {
+			return parsedTime, nil
+		}
+	}
+
+	now := self.clock.Now().In(self.location)
+
+	normalizedInput := whitespace.ReplaceAllString(strings.ToLower(trimmedInput), " ")
+
+	if parsedTime, ok, error := self.parseRelative(now, normalizedInput); ok || error != nil {
+		return parsedTime, error
+	}
+
+	if normalizedInput == "now" {
+		return now, nil
+	}
+
+	day, hasTimeOfDay, hour, minute, error := self.splitTimeOfDay(normalizedInput)
+
+	if error != nil {
+		return time.Time{}, fmt.Errorf("%q %w", input, error)
+	}
+
+	date, error := self.parseDay(now, day, input)
+
+	if error != nil {
+		return time.Time{}, error
+	}
+
+	// The time is set on the day instead of added to it so days with a DST change
+	// and days that resolve to their last second like eod still get the time asked for.
+	if hasTimeOfDay {
+		date = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, self.location)
+	}
+
+	return date, nil
+
+}
+
+// parseRelative handles in 3 days and +3d.
+// Minutes and hours keep the time of day everything else is a whole day.
+func (self Parser) parseRelative(now time.Time, input string) (time.Time, bool, error) {
+
+	sign, amount, unit := 1, "", ""
+
+	if match := inPattern.FindStringSubmatch(input); match != nil {
+		amount, unit = match[1], match[2]
+	} else if match := offsetPattern.FindStringSubmatch(input); match != nil {
+		amount, unit = match[2], match[3]
+		sign = lo.Ternary(match[1] == "-", -1, 1)
+	} else {
+		return time.Time{}, false, nil
+	}
+
+	count, error := strconv.Atoi(amount)
+
+	if error != nil {
+		return time.Time{}, true, fmt.Errorf("%q %w", input, ErrUnrecognized)
+	}
+
+	count *= sign
+
+	switch units[unit] {
+	case "minute":
+		return now.Add(time.Duration(count) * time.Minute), true, nil
+	case "hour":
+		return now.Add(time.Duration(count) * time.Hour), true, nil
+	case "day":
+		return startOfDay(now).AddDate(0, 0, count), true, nil
+	case "week":
+		return startOfDay(now).AddDate(0, 0, count*7), true, nil
+	case "month":
+		return startOfDay(now).AddDate(0, count, 0), true, nil
+	}
+
+	return time.Time{}, true, fmt.Errorf("%q %w", input, ErrUnrecognized)
+}
+
+// splitTimeOfDay pulls a trailing 17:00, 5pm or at 9:30am off the input.
+// A bare number isn't treated as a time because it's too easy to mistake.
+func (self Parser) splitTimeOfDay(input string) (string, bool, int, int, error) {
+
+	match := timeOfDayPattern.FindStringSubmatch(input)
+
+	if match == nil || match[3] == "" && match[4] == "" {
+		return input, false, 0, 0, nil
+	}
+
+	hour, _ := strconv.Atoi(match[2])
+	minute, _ := strconv.Atoi(lo.CoalesceOrEmpty(match[3], "0"))
+
+	switch match[4] {
+	case "am", "pm":
+
+		if hour < 1 || hour > 12 {
+			return "", false, 0, 0, ErrUnrecognized
+		}
+
+		hour = hour % 12
+
+		if match[4] == "pm" {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return "", false, 0, 0, ErrUnrecognized
+	}
+
+	return lo.CoalesceOrEmpty(match[1], "today"), true, hour, minute, nil
+}
+
+func (self Parser) parseDay(now time.Time, day, input string) (time.Time, error) {
+
+	today := startOfDay(now)
+
+	switch day {
+	case "today":
+		return today, nil
+	case "tomorrow", "tmr":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "eod":
+		return endOfDay(today), nil
+	case "eow":
+		return endOfDay(today.AddDate(0, 0, daysUntil(now.Weekday(), time.Sunday))), nil
+	case "eom":
+		return endOfDay(time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, self.location)), nil
+	case "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "next month":
+		return today.AddDate(0, 1, 0), nil
+	}
+
+	if weekday, ok := weekdays[strings.TrimPrefix(day, "next ")]; ok {
+
+		days := daysUntil(now.Weekday(), weekday)
+
+		if days == 0 && !strings.HasPrefix(day, "next ") {
+			return time.Time{}, AmbiguousError{
+				Input:       input,
+				Suggestions: []string{"today", "next " + day},
+			}
+		}
+
+		if days == 0 {
+			days = 7
+		}
+
+		return today.AddDate(0, 0, days), nil
+	}
+
+	if match := slashPattern.FindStringSubmatch(day); match != nil {
+		return self.parseSlashDate(now, match, input)
+	}
+
+	return time.Time{}, fmt.Errorf("%q %w", input, ErrUnrecognized)
+}
+
+// parseSlashDate only accepts 1/2 style dates when there's one way to read them.
+// 3/14 is March 14th but 3/4 could be March 4th or April 3rd.
+func (self Parser) parseSlashDate(now time.Time, match []string, input string) (time.Time, error) {
+
+	first, _ := strconv.Atoi(match[1])
+	second, _ := strconv.Atoi(match[2])
+
+	year := now.Year()
+
+	if match[3] != "" {
+		year, _ = strconv.Atoi(match[3])
+		year = lo.Ternary(year < 100, year+2000, year)
+	}
+
+	build := func(month, day int) (time.Time, bool) {
+
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, self.location)
+
+		return date, date.Month() == time.Month(month) && date.Day() == day
+	}
+
+	monthFirst, monthFirstOk := build(first, second)
+	dayFirst, dayFirstOk := build(second, first)
+
+	switch {
+	case monthFirstOk && dayFirstOk && first != second:
+		return time.Time{}, AmbiguousError{
+			Input: input,
+			Suggestions: []string{
+				monthFirst.Format(time.DateOnly),
+				dayFirst.Format(time.DateOnly),
+			},
+		}
+	case monthFirstOk:
+		return monthFirst, nil
+	case dayFirstOk:
+		return dayFirst, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q %w", input, ErrUnrecognized)
+}
+
+func startOfDay(moment time.Time) time.Time {
+
+	return time.Date(moment.Year(), moment.Month(), moment.Day(), 0, 0, 0, 0, moment.Location())
+}
+
+func endOfDay(day time.Time) time.Time {
+
+	return time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, day.Location())
+}
+
+// daysUntil counts the days from one weekday to the next occurrence of another.
+func daysUntil(from, to time.Weekday) int {
+
+	return (int(to) - int(from) + 7) % 7
+}
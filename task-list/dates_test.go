@@ -0,0 +1,116 @@
+package main_test
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/mini-clis/task-list/dates"
+	"github.com/mini-clis/task-list/flags"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Dates", func() {
+	assert := assert.New(GinkgoT())
+
+	location := time.FixedZone("EST", -5*60*60)
+
+	// Wednesday March 12th 2025 at 10:30.
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, location)
+
+	parser := dates.NewParser(dates.ClockFunc(func() time.Time { return now }), location)
+
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, location)
+	}
+
+	type ParseCase struct {
+		Input    string
+		Expected time.Time
+	}
+
+	lo.ForEach([]ParseCase{
+		{Input: "today", Expected: day(time.March, 12)},
+		{Input: "Tomorrow", Expected: day(time.March, 13)},
+		{Input: "tomorrow 17:00", Expected: day(time.March, 13).Add(17 * time.Hour)},
+		{Input: "today at 5pm", Expected: day(time.March, 12).Add(17 * time.Hour)},
+		{Input: "yesterday", Expected: day(time.March, 11)},
+		{Input: "fri", Expected: day(time.March, 14)},
+		{Input: "next fri", Expected: day(time.March, 14)},
+		{Input: "next wed", Expected: day(time.March, 19)},
+		{Input: "next mon 9:30am", Expected: day(time.March, 17).Add(9*time.Hour + 30*time.Minute)},
+		{Input: "in 3 days", Expected: day(time.March, 15)},
+		{Input: "in 2 weeks", Expected: day(time.March, 26)},
+		{Input: "in 90 minutes", Expected: now.Add(90 * time.Minute)},
+		{Input: "-7d", Expected: day(time.March, 5)},
+		{Input: "eod", Expected: day(time.March, 12).Add(23*time.Hour + 59*time.Minute + 59*time.Second)},
+		{Input: "eow", Expected: day(time.March, 16).Add(23*time.Hour + 59*time.Minute + 59*time.Second)},
+		{Input: "eod 17:00", Expected: day(time.March, 12).Add(17 * time.Hour)},
+		{Input: "eow 9:00", Expected: day(time.March, 16).Add(9 * time.Hour)},
+		{Input: "eom", Expected: day(time.March, 31).Add(23*time.Hour + 59*time.Minute + 59*time.Second)},
+		{Input: "2025-04-01", Expected: day(time.April, 1)},
+		{Input: "2025-04-01 08:15", Expected: day(time.April, 1).Add(8*time.Hour + 15*time.Minute)},
+		{Input: "2025-04-01T08:15:00Z", Expected: time.Date(2025, time.April, 1, 8, 15, 0, 0, time.UTC)},
+		{Input: "3/14", Expected: day(time.March, 14)},
+		{Input: "now", Expected: now},
+	}, func(parseCase ParseCase, index int) {
+		It(fmt.Sprintf("parses %q", parseCase.Input), func() {
+			parsedTime, err := parser.Parse(parseCase.Input)
+			assert.NoError(err)
+			assert.True(
+				parseCase.Expected.Equal(parsedTime),
+				fmt.Sprintf("expected %s got %s", parseCase.Expected, parsedTime),
+			)
+		})
+	})
+
+	It("keeps the time of day on a day the clocks change", func() {
+		newYork := lo.Must(time.LoadLocation("America/New_York"))
+
+		// Saturday March 8th 2025, the day before the clocks go forward.
+		beforeDST := time.Date(2025, time.March, 8, 10, 30, 0, 0, newYork)
+
+		newYorkParser := dates.NewParser(dates.ClockFunc(func() time.Time { return beforeDST }), newYork)
+
+		for input, hour := range map[string]int{"tomorrow 17:00": 17, "tomorrow 9am": 9} {
+			parsedTime, err := newYorkParser.Parse(input)
+			assert.NoError(err)
+			assert.Equal(time.Date(2025, time.March, 9, hour, 0, 0, 0, newYork), parsedTime, input)
+		}
+	})
+
+	It("reports a weekday that could mean today or next week as ambiguous", func() {
+		_, err := parser.Parse("wed")
+
+		var ambiguousError dates.AmbiguousError
+		assert.ErrorAs(err, &ambiguousError)
+		assert.Equal([]string{"today", "next wed"}, ambiguousError.Suggestions)
+	})
+
+	It("reports a slash date that could be read either way as ambiguous", func() {
+		_, err := parser.Parse("3/4")
+		assert.ErrorIs(err, dates.ErrAmbiguous)
+	})
+
+	lo.ForEach([]string{"someday", "tomorrow 25:00", "today 17", "in 3 fortnights"}, func(input string, index int) {
+		It(fmt.Sprintf("rejects %q", input), func() {
+			_, err := parser.Parse(input)
+			assert.ErrorIs(err, dates.ErrUnrecognized)
+		})
+	})
+
+	It("turns parse errors into flag errors", func() {
+		dueFlag := flags.NewDateFlagWithParser("due", parser)
+
+		assert.ErrorIs(dueFlag.Set("wed"), dates.ErrAmbiguous)
+		assert.Nil(dueFlag.Value())
+
+		assert.NoError(dueFlag.Set("next wed"))
+		assert.True(day(time.March, 19).Equal(*dueFlag.Value()))
+
+		assert.NoError(dueFlag.Set(flags.NO_DATE))
+		assert.Nil(dueFlag.Value())
+	})
+})
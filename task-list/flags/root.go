@@ -5,7 +5,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mini-clis/shared/custom_errors"
+	"github.com/mini-clis/task-list/dates"
+	"github.com/mini-clis/task-list/task"
 	"github.com/samber/lo"
 )
 
@@ -118,3 +122,116 @@ func (self *unionFlag) Set(value string) error {
 func (self unionFlag) Type() string {
 	return "string"
 }
+
+// NO_DATE clears a date that was set before.
+const NO_DATE = "none"
+
+// dateFlag accepts anything the dates parser understands
+// like today, tomorrow 17:00, next fri, in 3 days, eow and ISO dates.
+type dateFlag struct {
+	value    string
+	time     time.Time
+	flagName string
+	parser   dates.Parser
+}
+
+func NewDateFlag(flagName string) dateFlag {
+	return NewDateFlagWithParser(flagName, dates.DefaultParser())
+}
+
+// NewDateFlagWithParser lets tests pin the clock and timezone dates are resolved against.
+func NewDateFlagWithParser(flagName string, parser dates.Parser) dateFlag {
+	return dateFlag{
+		flagName: flagName,
+		parser:   parser,
+	}
+}
+
+func (self dateFlag) String() string {
+	return self.value
+}
+
+func (self *dateFlag) Set(value string) error {
+
+	if value == NO_DATE {
+		self.value = value
+		self.time = time.Time{}
+		return nil
+	}
+
+	parsedTime, error := self.parser.Parse(value)
+
+	if error != nil {
+		return fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, self.flagName, error)
+	}
+
+	self.value = value
+	self.time = parsedTime
+	return nil
+}
+
+func (self dateFlag) Type() string {
+	return "date"
+}
+
+// Value is nil when the flag wasn't set or was set to none.
+func (self dateFlag) Value() *time.Time {
+
+	if self.value == NO_DATE || self.value == "" {
+		return nil
+	}
+
+	return &self.time
+}
+
+// NO_REPEAT stops a task from repeating.
+const NO_REPEAT = "none"
+
+// repeatFlag accepts the rules task.ParseRecurrence understands
+// like daily, weekly on mon/wed, monthly on the 1st and every 3 days after completion.
+type repeatFlag struct {
+	value      string
+	recurrence task.Recurrence
+	flagName   string
+}
+
+func NewRepeatFlag(flagName string) repeatFlag {
+	return repeatFlag{flagName: flagName}
+}
+
+func (self repeatFlag) String() string {
+	return self.value
+}
+
+func (self *repeatFlag) Set(value string) error {
+
+	if value == NO_REPEAT {
+		self.value = value
+		self.recurrence = task.Recurrence{}
+		return nil
+	}
+
+	recurrence, error := task.ParseRecurrence(value)
+
+	if error != nil {
+		return fmt.Errorf("%w %s %w", custom_errors.InvalidFlag, self.flagName, error)
+	}
+
+	self.value = value
+	self.recurrence = recurrence
+	return nil
+}
+
+func (self repeatFlag) Type() string {
+	return "rule"
+}
+
+// Value is nil when the flag wasn't set or was set to none.
+func (self repeatFlag) Value() *task.Recurrence {
+
+	if self.value == NO_REPEAT || self.value == "" {
+		return nil
+	}
+
+	return &self.recurrence
+}
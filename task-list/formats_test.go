@@ -0,0 +1,285 @@
+package main_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mini-clis/task-list/task"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Formats", func() {
+	assert := assert.New(GinkgoT())
+
+	Context("todo.txt", func() {
+		fixture := lo.Must(os.ReadFile("testdata/todo.txt"))
+
+		read := func() []task.Task {
+			tasks, err := task.ReadTodoTxt(bytes.NewReader(fixture), task.DEFAULT_LIST)
+			assert.NoError(err)
+			return tasks
+		}
+
+		It("writes back the file it read", func() {
+			var buffer bytes.Buffer
+			assert.NoError(task.WriteTodoTxt(&buffer, read()))
+			assert.Equal(string(fixture), buffer.String())
+		})
+
+		It("maps priorities, completion and dates", func() {
+			tasks := read()
+
+			assert.Equal(
+				[]string{"high", "medium", "low", "low", "high", "medium", "low", "low", "low", "low", "high", "low"},
+				lo.Map(tasks, func(item task.Task, index int) string { return item.Priority.Value() }),
+			)
+			assert.Equal(
+				[]bool{false, false, false, false, false, true, true, false, false, false, true, false},
+				lo.Map(tasks, func(item task.Task, index int) bool { return item.Done() }),
+			)
+
+			callMom := tasks[4]
+			assert.Equal("Call Mom", callMom.Title)
+			assert.Equal(time.Date(2011, 3, 2, 0, 0, 0, 0, time.Local).UnixMicro(), callMom.CreatedAt())
+			assert.Equal(time.Date(2011, 3, 10, 0, 0, 0, 0, time.Local), *callMom.Due)
+
+			review := tasks[5]
+			assert.Equal(time.Date(2011, 3, 1, 0, 0, 0, 0, time.Local).UnixMicro(), review.CreatedAt())
+			assert.Equal(time.Date(2011, 3, 3, 0, 0, 0, 0, time.Local), review.UpdatedAt)
+		})
+
+		It("keeps projects, contexts and extras", func() {
+			tasks := read()
+
+			assert.Equal("Schedule Goodwill pickup", tasks[1].Title)
+			assert.Equal([]string{"GarageSale", "@phone"}, tasks[1].Tags)
+			assert.Equal("@GroceryStore Eskimo pies", tasks[3].Title)
+			assert.Equal([]string{"@GroceryStore"}, tasks[3].Tags)
+			assert.Equal("+1w", tasks[4].Metadata["rec"])
+			assert.Equal("Learn how to play +guitar every day see http://example.com", tasks[7].Title)
+			assert.Equal("Meeting at 12:30", tasks[8].Title)
+			assert.Equal("5:30pm", tasks[8].Metadata["time"])
+			assert.Equal("Water  the   plants", tasks[11].Title)
+			assert.Equal([]string{"a"}, tasks[11].Tags)
+		})
+
+		It("reads back the tasks it wrote", func() {
+			item := task.NewTask("Ship the release", "")
+			item.Priority = task.MEDIUM
+			item.Tags = []string{"work", "@office"}
+			item.Due = lo.ToPtr(time.Date(2025, 6, 1, 17, 30, 0, 0, time.Local))
+			item.Metadata = map[string]string{"estimate": "2h"}
+
+			line := item.TodoTxtLine()
+			assert.Regexp(`^\(B\) \d{4}-\d{2}-\d{2} Ship the release \+work @office due:2025-06-01T17:30 estimate:2h$`, line)
+
+			parsed := task.ParseTodoTxtLine(line, task.DEFAULT_LIST)
+			assert.Equal(item.Title, parsed.Title)
+			assert.Equal(item.Priority, parsed.Priority)
+			assert.Equal(item.Tags, parsed.Tags)
+			assert.Equal(item.Due.Unix(), parsed.Due.Unix())
+			assert.Equal(item.Metadata, parsed.Metadata)
+			assert.Equal(line, parsed.TodoTxtLine())
+		})
+	})
+
+	Context("CSV", func() {
+		mapping := lo.Must(task.ParseCSVMapping([]string{
+			"Summary=title", "Prio=priority", "Notes=description", "Due date=due", "Labels=tags",
+		}))
+
+		It("reads the valid rows and reports every problem with the others", func() {
+			tasks, rowErrors, err := task.ReadCSV(bytes.NewReader(lo.Must(os.ReadFile("testdata/sprint.csv"))), task.DEFAULT_LIST, mapping)
+			assert.NoError(err)
+
+			assert.Equal([]string{"Deploy the API", "Write the docs"}, lo.Map(tasks, func(item task.Task, index int) string { return item.Title }))
+			assert.Equal(task.HIGH, tasks[0].Priority)
+			assert.Equal("Roll out\nbehind a flag", tasks[0].Description)
+			assert.Equal([]string{"work", "backend"}, tasks[0].Tags)
+			assert.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local), *tasks[0].Due)
+
+			assert.Equal(
+				[]string{"row 4 priority", "row 4 due", "row 5 title"},
+				lo.Map(rowErrors, func(rowError task.RowError, index int) string {
+					return fmt.Sprintf("row %d %s", rowError.Row, rowError.Column)
+				}),
+			)
+		})
+
+		It("writes the columns it's asked for and reads them back", func() {
+			item := task.NewTask("Ship, then \"celebrate\"", "")
+			item.Priority = task.MEDIUM
+			item.Status = task.DONE
+			item.Tags = []string{"work", "release"}
+			item.Metadata = map[string]string{"sprint": "7"}
+
+			columns := lo.Must(task.ParseCSVColumns([]string{"Title", "priority", "complete", "tags", "metadata"}))
+
+			var buffer bytes.Buffer
+			assert.NoError(task.WriteCSV(&buffer, []task.Task{item}, columns))
+			assert.Equal("title,priority,complete,tags,metadata\n\"Ship, then \"\"celebrate\"\"\",medium,true,\"work,release\",\"{\"\"sprint\"\":\"\"7\"\"}\"\n", buffer.String())
+
+			tasks, rowErrors, err := task.ReadCSV(&buffer, task.DEFAULT_LIST, nil)
+			assert.NoError(err)
+			assert.Empty(rowErrors)
+			assert.Equal(item.Title, tasks[0].Title)
+			assert.Equal(item.Priority, tasks[0].Priority)
+			assert.Equal(item.Done(), tasks[0].Done())
+			assert.Equal(item.Tags, tasks[0].Tags)
+			assert.Equal(item.Metadata, tasks[0].Metadata)
+		})
+
+		It("only maps to fields a task can be imported with", func() {
+			_, err := task.ParseCSVMapping([]string{"Key=id"})
+			assert.ErrorIs(err, task.ErrUnknownColumn)
+
+			_, err = task.ParseCSVColumns([]string{"colour"})
+			assert.ErrorIs(err, task.ErrUnknownColumn)
+		})
+	})
+
+	Context("Markdown", func() {
+		read := func() []task.Task {
+			tasks, err := task.ReadMarkdown(bytes.NewReader(lo.Must(os.ReadFile("testdata/checklist.md"))), task.DEFAULT_LIST)
+			assert.NoError(err)
+			return tasks
+		}
+
+		It("reads checklist items with the priority of their heading", func() {
+			tasks := read()
+
+			assert.Equal(
+				[]string{"Deploy the API", "Write the migration", "Back up the database", "Dry run on staging", "Update the changelog", "Tidy the README"},
+				lo.Map(tasks, func(item task.Task, index int) string { return item.Title }),
+			)
+			assert.Equal(
+				[]string{"high", "high", "high", "high", "medium", "low"},
+				lo.Map(tasks, func(item task.Task, index int) string { return item.Priority.Value() }),
+			)
+			assert.Equal(
+				[]bool{true, false, false, true, false, false},
+				lo.Map(tasks, func(item task.Task, index int) bool { return item.Done() }),
+			)
+			assert.Equal("Roll out behind a flag.\n\nTurn it on for everyone on Friday.", tasks[0].Description)
+			assert.Equal(tasks[1].Id(), tasks[2].ParentId)
+			assert.Equal(tasks[1].Id(), tasks[3].ParentId)
+		})
+
+		It("writes a checklist it reads back the same", func() {
+			var buffer bytes.Buffer
+			assert.NoError(task.WriteMarkdown(&buffer, read()))
+
+			assert.Equal(`## High
+
+- [x] Deploy the API
+  Roll out behind a flag.
+
+  Turn it on for everyone on Friday.
+- [ ] Write the migration
+  - [ ] Back up the database
+  - [x] Dry run on staging
+
+## Medium
+
+- [ ] Update the changelog
+
+## Low
+
+- [ ] Tidy the README
+`, buffer.String())
+
+			tasks, err := task.ReadMarkdown(bytes.NewReader(buffer.Bytes()), task.DEFAULT_LIST)
+			assert.NoError(err)
+
+			var again bytes.Buffer
+			assert.NoError(task.WriteMarkdown(&again, tasks))
+			assert.Equal(buffer.String(), again.String())
+		})
+	})
+
+	Context("iCalendar", func() {
+		readFixture := func(name string) []task.Task {
+			tasks, err := task.ReadICS(bytes.NewReader(lo.Must(os.ReadFile(name))), task.DEFAULT_LIST)
+			assert.NoError(err)
+			return tasks
+		}
+
+		It("reads the to-dos another calendar app wrote", func() {
+			tasks := readFixture("testdata/calendar.ics")
+			assert.Len(tasks, 3)
+
+			release := tasks[0]
+			assert.Equal("Ship the release, then celebrate", release.Title)
+			assert.Equal(
+				"Tag the build; push the images\nThen write the announcement that goes out to everyone on the mailing list with a link to the changelog.",
+				release.Description,
+			)
+			assert.Equal(task.HIGH, release.Priority)
+			assert.False(release.Done())
+			assert.Equal(time.Date(2025, 6, 1, 15, 0, 0, 0, time.UTC), release.Due.UTC())
+			assert.Equal([]string{"work", "release", "team"}, release.Tags)
+			assert.Equal(time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC).UnixMicro(), release.CreatedAt())
+			assert.Equal(time.Date(2025, 5, 2, 10, 15, 0, 0, time.UTC), release.UpdatedAt.UTC())
+
+			notes := tasks[1]
+			assert.Equal(task.MEDIUM, notes.Priority)
+			assert.Equal(release.Id(), notes.ParentId)
+			assert.Equal(time.Date(2025, 5, 30, 0, 0, 0, 0, time.Local), *notes.Due)
+			assert.Equal("FREQ=WEEKLY;BYDAY=MO,WE", notes.Repeat.String())
+
+			room := tasks[2]
+			assert.Equal(task.LOW, room.Priority)
+			assert.True(room.Done())
+			assert.Equal(time.Date(2025, 4, 2, 8, 0, 0, 0, time.UTC), room.UpdatedAt.UTC())
+		})
+
+		It("keeps its uid out of todo.txt and CSV exports", func() {
+			release := readFixture("testdata/calendar.ics")[0]
+			assert.NotEmpty(release.Metadata)
+
+			line := release.TodoTxtLine()
+			assert.NotContains(line, "ics:")
+			assert.Empty(task.ParseTodoTxtLine(line, task.DEFAULT_LIST).UserMetadata())
+
+			var buffer bytes.Buffer
+			assert.NoError(task.WriteCSV(&buffer, []task.Task{release}, []string{"title", "metadata"}))
+			assert.NotContains(buffer.String(), "ics:")
+		})
+
+		It("writes back the calendar it read", func() {
+			var buffer bytes.Buffer
+			assert.NoError(task.WriteICS(&buffer, readFixture("testdata/tasks.ics")))
+			assert.Equal(string(lo.Must(os.ReadFile("testdata/tasks.ics"))), buffer.String())
+		})
+
+		It("folds long lines without splitting characters", func() {
+			item := task.NewTask(strings.Repeat("Café, crème; brûlée\\ ", 8), "first line\nsecond line")
+
+			var buffer bytes.Buffer
+			assert.NoError(task.WriteICS(&buffer, []task.Task{item}))
+
+			for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\r\n"), "\r\n") {
+				assert.LessOrEqual(len(line), 75)
+				assert.True(utf8.ValidString(line), line)
+			}
+
+			assert.Contains(buffer.String(), `DESCRIPTION:first line\nsecond line`)
+
+			tasks, err := task.ReadICS(&buffer, task.DEFAULT_LIST)
+			assert.NoError(err)
+			assert.Equal(item.Title, tasks[0].Title)
+			assert.Equal(item.Description, tasks[0].Description)
+		})
+
+		It("fails on a to-do that isn't closed", func() {
+			_, err := task.ReadICS(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Open\r\nEND:VCALENDAR\r\n"), task.DEFAULT_LIST)
+			assert.ErrorIs(err, task.ErrInvalidICS)
+		})
+	})
+})
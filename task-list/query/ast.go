@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mini-clis/task-list/task"
+)
+
+// Node is a part of a parsed query.
+// String writes it back out with brackets around every and/or so the grouping is clear.
+type Node interface {
+	Match(item task.Task, env Env) bool
+	String() string
+}
+
+type And struct {
+	Left, Right Node
+}
+
+func (self And) Match(item task.Task, env Env) bool {
+
+	return self.Left.Match(item, env) && self.Right.Match(item, env)
+}
+
+func (self And) String() string {
+
+	return fmt.Sprintf("(%s and %s)", self.Left, self.Right)
+}
+
+type Or struct {
+	Left, Right Node
+}
+
+func (self Or) Match(item task.Task, env Env) bool {
+
+	return self.Left.Match(item, env) || self.Right.Match(item, env)
+}
+
+func (self Or) String() string {
+
+	return fmt.Sprintf("(%s or %s)", self.Left, self.Right)
+}
+
+type Not struct {
+	Operand Node
+}
+
+func (self Not) Match(item task.Task, env Env) bool {
+
+	return !self.Operand.Match(item, env)
+}
+
+func (self Not) String() string {
+
+	return fmt.Sprintf("not %s", self.Operand)
+}
+
+// Comparison is a field compared with a value like priority >= medium.
+// The value is checked when the query is parsed so matching can't fail.
+type Comparison struct {
+	Field    string
+	Operator string
+	Value    string
+	match    predicate
+}
+
+func (self Comparison) Match(item task.Task, env Env) bool {
+
+	return self.match(item, env)
+}
+
+func (self Comparison) String() string {
+
+	value := self.Value
+
+	if value == "" || strings.ContainsAny(value, " ()\"'=<>!~") {
+		value = strconv.Quote(value)
+	}
+
+	return fmt.Sprintf("%s %s %s", self.Field, self.Operator, value)
+}
+
+// Flag is a yes or no field on its own like complete or overdue.
+type Flag struct {
+	Field string
+	match predicate
+}
+
+func (self Flag) Match(item task.Task, env Env) bool {
+
+	return self.match(item, env)
+}
+
+func (self Flag) String() string {
+
+	return self.Field
+}
@@ -0,0 +1,237 @@
+package query
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+)
+
+type fieldKind int
+
+const (
+	textKind fieldKind = iota
+	idKind
+	tagKind
+	priorityKind
+	dateKind
+	boolKind
+)
+
+// NONE compares with tasks that don't have a date like due = none.
+const NONE = "none"
+
+type field struct {
+	kind fieldKind
+	text func(item task.Task) string
+	date func(item task.Task) *time.Time
+	flag predicate
+}
+
+var fields = map[string]field{
+	"title":       {kind: textKind, text: func(item task.Task) string { return item.Title }},
+	"description": {kind: textKind, text: func(item task.Task) string { return item.Description }},
+	"list":        {kind: textKind, text: func(item task.Task) string { return item.List }},
+	"status":      {kind: textKind, text: func(item task.Task) string { return item.Status.Value() }},
+	"id":          {kind: idKind, text: func(item task.Task) string { return item.Id() }},
+	"priority":    {kind: priorityKind},
+	"tag":         {kind: tagKind},
+	"tags":        {kind: tagKind},
+	"created": {kind: dateKind, date: func(item task.Task) *time.Time {
+		return lo.ToPtr(time.UnixMicro(item.CreatedAt()))
+	}},
+	"updated": {kind: dateKind, date: func(item task.Task) *time.Time {
+		return lo.Ternary(item.UpdatedAt.IsZero(), nil, &item.UpdatedAt)
+	}},
+	"due": {kind: dateKind, date: func(item task.Task) *time.Time { return item.Due }},
+	"complete": {kind: boolKind, flag: func(item task.Task, env Env) bool {
+		return item.Done()
+	}},
+	"done": {kind: boolKind, flag: func(item task.Task, env Env) bool {
+		return item.Done()
+	}},
+	"overdue": {kind: boolKind, flag: func(item task.Task, env Env) bool {
+		return item.Overdue(env.Now)
+	}},
+	"blocked": {kind: boolKind, flag: func(item task.Task, env Env) bool {
+		return len(item.Blockers(env.Tasks)) > 0
+	}},
+	"recurring": {kind: boolKind, flag: func(item task.Task, env Env) bool {
+		return item.Repeat != nil
+	}},
+}
+
+// FieldNames is every field a query can use in alphabetical order.
+func FieldNames() []string {
+
+	names := lo.Keys(fields)
+
+	slices.Sort(names)
+
+	return names
+}
+
+var operatorsByKind = map[fieldKind][]string{
+	textKind:     {"=", "!=", "~", "!~"},
+	idKind:       {"=", "!=", "~", "!~"},
+	tagKind:      {"=", "!=", "~", "!~"},
+	priorityKind: {"=", "!=", "<", "<=", ">", ">="},
+	dateKind:     {"=", "!=", "<", "<=", ">", ">="},
+	boolKind:     {"=", "!="},
+}
+
+// compile checks the operator and value suit the field and turns them into a predicate.
+func (self field) compile(state *parser, operator, value token) (predicate, error) {
+
+	if !lo.Contains(operatorsByKind[self.kind], operator.text) {
+		return nil, syntaxError(
+			state.input,
+			operator.column,
+			"%s doesn't work with this field try one of %s",
+			operator.text,
+			strings.Join(operatorsByKind[self.kind], " "),
+		)
+	}
+
+	negated := operator.text == "!=" || operator.text == "!~"
+
+	var match predicate
+
+	switch self.kind {
+	case textKind, idKind:
+
+		match = func(item task.Task, env Env) bool {
+
+			text := self.text(item)
+
+			switch {
+			case strings.HasSuffix(operator.text, "~"):
+				return strings.Contains(strings.ToLower(text), strings.ToLower(value.text))
+			case self.kind == idKind:
+				// Ids match by their start like everywhere else an id is taken.
+				return strings.HasPrefix(text, value.text)
+			}
+
+			return strings.EqualFold(text, value.text)
+		}
+
+	case tagKind:
+
+		match = func(item task.Task, env Env) bool {
+
+			return lo.SomeBy(item.Tags, func(tag string) bool {
+
+				if strings.HasSuffix(operator.text, "~") {
+					return strings.Contains(strings.ToLower(tag), strings.ToLower(value.text))
+				}
+
+				return strings.EqualFold(tag, value.text)
+			})
+		}
+
+	case priorityKind:
+
+		parsedPriority, error := task.ParsePriority(strings.ToLower(value.text))
+
+		if error != nil {
+			return nil, syntaxError(
+				state.input,
+				value.column,
+				"%q isn't a priority try one of %s",
+				value.text,
+				strings.Join(task.AllowedProrities, ", "),
+			)
+		}
+
+		return func(item task.Task, env Env) bool {
+			return compare(operator.text, cmp.Compare(item.Priority.Order(), parsedPriority.Order()))
+		}, nil
+
+	case dateKind:
+
+		if strings.EqualFold(value.text, NONE) {
+
+			if operator.text != "=" && operator.text != "!=" {
+				return nil, syntaxError(state.input, operator.column, "%s can only be compared with = or !=", NONE)
+			}
+
+			match = func(item task.Task, env Env) bool {
+				return self.date(item) == nil
+			}
+
+			break
+		}
+
+		moment, error := state.dates.Parse(value.text)
+
+		if error != nil {
+			return nil, syntaxError(state.input, value.column, "%s", error)
+		}
+
+		if operator.text != "=" && operator.text != "!=" {
+
+			return func(item task.Task, env Env) bool {
+
+				date := self.date(item)
+
+				return date != nil && compare(operator.text, date.Compare(moment))
+			}, nil
+		}
+
+		// = compares the day so created = today matches anything created today.
+		match = func(item task.Task, env Env) bool {
+
+			date := self.date(item)
+
+			if date == nil {
+				return false
+			}
+
+			year, month, day := date.In(moment.Location()).Date()
+			otherYear, otherMonth, otherDay := moment.Date()
+
+			return year == otherYear && month == otherMonth && day == otherDay
+		}
+
+	case boolKind:
+
+		expected, error := strconv.ParseBool(value.text)
+
+		if error != nil {
+			return nil, syntaxError(state.input, value.column, "%q isn't true or false", value.text)
+		}
+
+		match = func(item task.Task, env Env) bool {
+			return self.flag(item, env) == expected
+		}
+	}
+
+	if negated {
+		return func(item task.Task, env Env) bool { return !match(item, env) }, nil
+	}
+
+	return match, nil
+}
+
+// compare turns the result of cmp.Compare into the answer for an ordering operator.
+func compare(operator string, result int) bool {
+
+	switch operator {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	}
+
+	return result >= 0
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	END tokenKind = iota
+	WORD
+	STRING
+	OPERATOR
+	OPEN
+	CLOSE
+)
+
+// token remembers the column it started at so errors can point at it.
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+var operators = []string{"<=", ">=", "!=", "!~", "=", "<", ">", "~"}
+
+// lex splits a query into words, quoted strings, operators and brackets.
+// Words run until a space, a bracket or an operator so -7d and 2025-04-01 are single words.
+func lex(input string) ([]token, error) {
+
+	runes := []rune(input)
+	tokens := []token{}
+
+	for index := 0; index < len(runes); {
+
+		character := runes[index]
+		column := index + 1
+
+		switch {
+		case unicode.IsSpace(character):
+			index++
+
+		case character == '(':
+			tokens = append(tokens, token{OPEN, "(", column})
+			index++
+
+		case character == ')':
+			tokens = append(tokens, token{CLOSE, ")", column})
+			index++
+
+		case character == '"' || character == '\'':
+
+			var builder strings.Builder
+
+			end := index + 1
+
+			for ; end < len(runes) && runes[end] != character; end++ {
+
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+
+				builder.WriteRune(runes[end])
+			}
+
+			if end == len(runes) {
+				return nil, syntaxError(input, column, "this quote is never closed")
+			}
+
+			tokens = append(tokens, token{STRING, builder.String(), column})
+			index = end + 1
+
+		default:
+
+			if operator, ok := operatorAt(runes, index); ok {
+				tokens = append(tokens, token{OPERATOR, operator, column})
+				index += len(operator)
+				continue
+			}
+
+			end := index
+
+			for end < len(runes) && !endsWord(runes, end) {
+				end++
+			}
+
+			tokens = append(tokens, token{WORD, string(runes[index:end]), column})
+			index = end
+		}
+	}
+
+	return append(tokens, token{END, "", len(runes) + 1}), nil
+}
+
+func operatorAt(runes []rune, index int) (string, bool) {
+
+	for _, operator := range operators {
+
+		if strings.HasPrefix(string(runes[index:]), operator) {
+			return operator, true
+		}
+	}
+
+	return "", false
+}
+
+func endsWord(runes []rune, index int) bool {
+
+	character := runes[index]
+
+	if unicode.IsSpace(character) || character == '(' || character == ')' {
+		return true
+	}
+
+	_, ok := operatorAt(runes, index)
+
+	return ok
+}
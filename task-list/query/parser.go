@@ -0,0 +1,198 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mini-clis/task-list/dates"
+	"github.com/mini-clis/task-list/task"
+)
+
+// parser turns tokens into a tree by recursive descent.
+// not binds tighter than and which binds tighter than or.
+//
+//	or         = and { "or" and }
+//	and        = not { "and" not }
+//	not        = "not" not | primary
+//	primary    = "(" or ")" | field operator value | field
+type parser struct {
+	tokens   []token
+	position int
+	input    string
+	dates    dates.Parser
+}
+
+func (self *parser) peek() token {
+
+	return self.tokens[self.position]
+}
+
+func (self *parser) next() token {
+
+	current := self.tokens[self.position]
+
+	if current.kind != END {
+		self.position++
+	}
+
+	return current
+}
+
+func (self *parser) keyword(word string) bool {
+
+	current := self.peek()
+
+	return current.kind == WORD && strings.EqualFold(current.text, word)
+}
+
+func (self *parser) parseOr() (Node, error) {
+
+	left, error := self.parseAnd()
+
+	if error != nil {
+		return nil, error
+	}
+
+	for self.keyword("or") {
+
+		self.next()
+
+		right, error := self.parseAnd()
+
+		if error != nil {
+			return nil, error
+		}
+
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (self *parser) parseAnd() (Node, error) {
+
+	left, error := self.parseNot()
+
+	if error != nil {
+		return nil, error
+	}
+
+	for self.keyword("and") {
+
+		self.next()
+
+		right, error := self.parseNot()
+
+		if error != nil {
+			return nil, error
+		}
+
+		left = And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (self *parser) parseNot() (Node, error) {
+
+	if !self.keyword("not") {
+		return self.parsePrimary()
+	}
+
+	self.next()
+
+	operand, error := self.parseNot()
+
+	if error != nil {
+		return nil, error
+	}
+
+	return Not{Operand: operand}, nil
+}
+
+func (self *parser) parsePrimary() (Node, error) {
+
+	current := self.next()
+
+	if current.kind == OPEN {
+
+		node, error := self.parseOr()
+
+		if error != nil {
+			return nil, error
+		}
+
+		if closing := self.next(); closing.kind != CLOSE {
+			return nil, syntaxError(
+				self.input,
+				closing.column,
+				"expected ) to close the ( at column %d but found %s",
+				current.column,
+				describe(closing),
+			)
+		}
+
+		return node, nil
+	}
+
+	if current.kind != WORD || self.isKeyword(current) {
+		return nil, syntaxError(self.input, current.column, "expected a field, not or ( but found %s", describe(current))
+	}
+
+	name := strings.ToLower(current.text)
+
+	field, ok := fields[name]
+
+	if !ok {
+		return nil, syntaxError(
+			self.input,
+			current.column,
+			"there's no field called %q try one of %s",
+			current.text,
+			strings.Join(FieldNames(), ", "),
+		)
+	}
+
+	if self.peek().kind != OPERATOR {
+
+		if field.kind != boolKind {
+			return nil, syntaxError(self.input, self.peek().column, "expected an operator like = after %s", name)
+		}
+
+		return Flag{Field: name, match: field.flag}, nil
+	}
+
+	operator := self.next()
+	value := self.next()
+
+	if value.kind != WORD && value.kind != STRING {
+		return nil, syntaxError(self.input, value.column, "expected a value after %s but found %s", operator.text, describe(value))
+	}
+
+	match, error := field.compile(self, operator, value)
+
+	if error != nil {
+		return nil, error
+	}
+
+	return Comparison{Field: name, Operator: operator.text, Value: value.text, match: match}, nil
+}
+
+func (self *parser) isKeyword(current token) bool {
+
+	word := strings.ToLower(current.text)
+
+	return word == "and" || word == "or" || word == "not"
+}
+
+func describe(current token) string {
+
+	if current.kind == END {
+		return "the end of the query"
+	}
+
+	return fmt.Sprintf("%q", current.text)
+}
+
+// predicate is what a comparison or flag compiles into.
+type predicate func(item task.Task, env Env) bool
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mini-clis/task-list/dates"
+	"github.com/mini-clis/task-list/task"
+	"github.com/samber/lo"
+)
+
+var ErrSyntax = errors.New("isn't a query task-list understands")
+
+// SyntaxError points at the column of the query where parsing went wrong.
+// Columns start at 1 and count characters not bytes.
+type SyntaxError struct {
+	Input   string
+	Column  int
+	Message string
+}
+
+func (self SyntaxError) Error() string {
+
+	return fmt.Sprintf(
+		"%q %s %s at column %d\n  %s\n  %s^",
+		self.Input,
+		ErrSyntax,
+		self.Message,
+		self.Column,
+		self.Input,
+		strings.Repeat(" ", self.Column-1),
+	)
+}
+
+func (self SyntaxError) Unwrap() error {
+
+	return ErrSyntax
+}
+
+func syntaxError(input string, column int, format string, arguments ...any) SyntaxError {
+
+	return SyntaxError{Input: input, Column: column, Message: fmt.Sprintf(format, arguments...)}
+}
+
+// Env is what a query is matched against besides the task itself.
+// Tasks is every task in the store so blocked can look up blockers.
+type Env struct {
+	Now   time.Time
+	Tasks []task.Task
+}
+
+// Query is the root of the tree a query parses into.
+type Query struct {
+	Node
+	input string
+}
+
+func (self Query) Input() string {
+
+	return self.input
+}
+
+// Match is false for the zero Query so a --where that wasn't passed matches nothing.
+func (self Query) Match(item task.Task, env Env) bool {
+
+	return self.Node != nil && self.Node.Match(item, env)
+}
+
+// Filter keeps the tasks the query matches.
+func (self Query) Filter(tasks []task.Task, env Env) []task.Task {
+
+	return lo.Filter(tasks, func(item task.Task, index int) bool {
+		return self.Match(item, env)
+	})
+}
+
+// Parser resolves dates in queries like created > -7d with its date parser.
+type Parser struct {
+	dates dates.Parser
+}
+
+func NewParser(dateParser dates.Parser) Parser {
+
+	return Parser{dateParser}
+}
+
+func Parse(input string) (Query, error) {
+
+	return NewParser(dates.DefaultParser()).Parse(input)
+}
+
+func (self Parser) Parse(input string) (Query, error) {
+
+	tokens, error := lex(input)
+
+	if error != nil {
+		return Query{}, error
+	}
+
+	state := &parser{tokens: tokens, input: input, dates: self.dates}
+
+	if state.peek().kind == END {
+		return Query{}, syntaxError(input, 1, "the query is empty")
+	}
+
+	node, error := state.parseOr()
+
+	if error != nil {
+		return Query{}, error
+	}
+
+	if next := state.peek(); next.kind != END {
+		return Query{}, syntaxError(input, next.column, "expected and, or or the end of the query but found %s", describe(next))
+	}
+
+	return Query{Node: node, input: input}, nil
+}
@@ -0,0 +1,114 @@
+package main_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mini-clis/task-list/dates"
+	"github.com/mini-clis/task-list/query"
+	"github.com/mini-clis/task-list/task"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Queries", func() {
+	assert := assert.New(GinkgoT())
+
+	now := time.Now()
+
+	parser := query.NewParser(dates.NewParser(dates.ClockFunc(func() time.Time { return now }), time.Local))
+
+	newTask := func(title string, priority string, complete bool, tags ...string) task.Task {
+		item := task.NewTask(title, "")
+		item.Priority, _ = task.ParsePriority(priority)
+		item = item.MarkComplete(complete)
+		item.Tags = tags
+		return item
+	}
+
+	deploy := newTask("Deploy the API", "high", false, "work")
+	report := newTask("Write the report", "medium", true, "work")
+	groceries := newTask("Buy groceries", "low", false, "home")
+	groceries.Due = lo.ToPtr(now.AddDate(0, 0, -2))
+
+	tasks := []task.Task{deploy, report, groceries}
+
+	titles := func(input string) []string {
+		parsedQuery, err := parser.Parse(input)
+		assert.NoError(err)
+
+		return lo.Map(parsedQuery.Filter(tasks, query.Env{Now: now, Tasks: tasks}), func(item task.Task, index int) string {
+			return item.Title
+		})
+	}
+
+	type ParseCase struct {
+		Input string
+		Tree  string
+	}
+
+	lo.ForEach([]ParseCase{
+		{Input: "complete", Tree: "complete"},
+		{Input: "title ~ deploy or title ~ report and not complete", Tree: "(title ~ deploy or (title ~ report and not complete))"},
+		{Input: "(title ~ deploy or title ~ report) and not complete", Tree: "((title ~ deploy or title ~ report) and not complete)"},
+		{Input: `title ~ "the api" AND NOT NOT done`, Tree: `(title ~ "the api" and not not done)`},
+		{Input: "priority>=medium and due<=-1d", Tree: "(priority >= medium and due <= -1d)"},
+	}, func(parseCase ParseCase, index int) {
+		It(fmt.Sprintf("parses %q", parseCase.Input), func() {
+			parsedQuery, err := parser.Parse(parseCase.Input)
+			assert.NoError(err)
+			assert.Equal(parseCase.Tree, parsedQuery.String())
+		})
+	})
+
+	type MatchCase struct {
+		Input    string
+		Expected []string
+	}
+
+	lo.ForEach([]MatchCase{
+		{Input: "priority >= medium", Expected: []string{"Deploy the API", "Write the report"}},
+		{Input: "priority < high and not complete", Expected: []string{"Buy groceries"}},
+		{Input: `priority >= medium and not complete and (title ~ "deploy" or created > -7d)`, Expected: []string{"Deploy the API"}},
+		{Input: "tag = work and complete = false", Expected: []string{"Deploy the API"}},
+		{Input: "tag != work", Expected: []string{"Buy groceries"}},
+		{Input: "overdue", Expected: []string{"Buy groceries"}},
+		{Input: "due = none", Expected: []string{"Deploy the API", "Write the report"}},
+		{Input: "due < today", Expected: []string{"Buy groceries"}},
+		{Input: "created = today and title !~ report", Expected: []string{"Deploy the API", "Buy groceries"}},
+		{Input: "created < -7d", Expected: []string{}},
+	}, func(matchCase MatchCase, index int) {
+		It(fmt.Sprintf("matches %q", matchCase.Input), func() {
+			assert.Equal(matchCase.Expected, titles(matchCase.Input))
+		})
+	})
+
+	type ErrorCase struct {
+		Input  string
+		Column int
+	}
+
+	lo.ForEach([]ErrorCase{
+		{Input: "", Column: 1},
+		{Input: "priority >= urgent", Column: 13},
+		{Input: "priority >=", Column: 12},
+		{Input: "title ~ deploy and", Column: 19},
+		{Input: "colour = red", Column: 1},
+		{Input: "(complete or overdue", Column: 21},
+		{Input: "title = 'open", Column: 9},
+		{Input: "title > b", Column: 7},
+		{Input: "title", Column: 6},
+		{Input: "complete overdue", Column: 10},
+		{Input: "due > someday", Column: 7},
+	}, func(errorCase ErrorCase, index int) {
+		It(fmt.Sprintf("points at column %d of %q", errorCase.Column, errorCase.Input), func() {
+			_, err := parser.Parse(errorCase.Input)
+			assert.ErrorIs(err, query.ErrSyntax)
+
+			var syntaxError query.SyntaxError
+			assert.ErrorAs(err, &syntaxError)
+			assert.Equal(errorCase.Column, syntaxError.Column)
+		})
+	})
+})
@@ -0,0 +1,77 @@
+package main_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mini-clis/task-list/task"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Recurrence", func() {
+	assert := assert.New(GinkgoT())
+
+	location := time.FixedZone("EST", -5*60*60)
+
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, location)
+	}
+
+	type RuleCase struct {
+		Input string
+		Rule  string
+	}
+
+	lo.ForEach([]RuleCase{
+		{Input: "daily", Rule: "FREQ=DAILY"},
+		{Input: "every 3 days", Rule: "FREQ=DAILY;INTERVAL=3"},
+		{Input: "weekly on Mon/Wed", Rule: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{Input: "every 2 weeks on fri, tue", Rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,FR"},
+		{Input: "monthly on the 1st", Rule: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{Input: "every 3 days after completion", Rule: "FREQ=DAILY;INTERVAL=3;FROM=COMPLETION"},
+		{Input: "FREQ=WEEKLY;BYDAY=MO,WE", Rule: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{Input: "RRULE:FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=15", Rule: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=15"},
+	}, func(ruleCase RuleCase, index int) {
+		It(fmt.Sprintf("parses %q", ruleCase.Input), func() {
+			recurrence, err := task.ParseRecurrence(ruleCase.Input)
+			assert.NoError(err)
+			assert.Equal(ruleCase.Rule, recurrence.String())
+		})
+	})
+
+	lo.ForEach([]string{"sometimes", "daily on mon", "weekly on someday", "monthly on the 40th", "FREQ=YEARLY"}, func(input string, index int) {
+		It(fmt.Sprintf("rejects %q", input), func() {
+			_, err := task.ParseRecurrence(input)
+			assert.ErrorIs(err, task.ErrInvalidRecurrence)
+		})
+	})
+
+	type NextCase struct {
+		Rule     string
+		Due      time.Time
+		Now      time.Time
+		Expected time.Time
+	}
+
+	lo.ForEach([]NextCase{
+		// Wednesday March 12th 2025.
+		{Rule: "daily", Due: day(time.March, 12), Now: day(time.March, 12), Expected: day(time.March, 13)},
+		{Rule: "weekly on mon/wed", Due: day(time.March, 12), Now: day(time.March, 12), Expected: day(time.March, 17)},
+		{Rule: "weekly on mon/wed", Due: day(time.March, 10), Now: day(time.March, 10), Expected: day(time.March, 12)},
+		{Rule: "every 2 weeks on mon", Due: day(time.March, 10), Now: day(time.March, 10), Expected: day(time.March, 24)},
+		{Rule: "monthly on the 1st", Due: day(time.March, 1), Now: day(time.March, 1), Expected: day(time.April, 1)},
+		{Rule: "monthly on the 31st", Due: day(time.March, 31), Now: day(time.March, 31), Expected: day(time.April, 30)},
+		{Rule: "every 3 days after completion", Due: day(time.March, 1), Now: day(time.March, 12), Expected: day(time.March, 15)},
+		{Rule: "daily", Due: day(time.March, 1), Now: day(time.March, 12), Expected: day(time.March, 12)},
+	}, func(nextCase NextCase, index int) {
+		It(fmt.Sprintf("moves %q on from %s", nextCase.Rule, nextCase.Due.Format(time.DateOnly)), func() {
+			recurrence, err := task.ParseRecurrence(nextCase.Rule)
+			assert.NoError(err)
+
+			next := recurrence.NextDue(&nextCase.Due, nextCase.Now.Add(9*time.Hour))
+			assert.True(nextCase.Expected.Equal(next), "expected %s got %s", nextCase.Expected, next)
+		})
+	})
+})
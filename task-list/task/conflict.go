@@ -0,0 +1,185 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+var ErrConflict = errors.New("Task was changed by another process")
+
+// ConflictError is returned when the stored task isn't the revision that was read.
+// Yours is the edited task and is nil when nothing was edited like when deleting.
+// Theirs is the task that is stored now.
+type ConflictError struct {
+	Revision int
+	Yours    *Task
+	Theirs   Task
+}
+
+func (self ConflictError) Error() string {
+
+	theirs, _ := self.Theirs.ToJSON()
+
+	message := fmt.Sprintf(
+		"%s %s you read revision %d but revision %d is stored",
+		ErrConflict,
+		self.Theirs.id,
+		self.Revision,
+		self.Theirs.revision,
+	)
+
+	if self.Yours != nil {
+		yours, _ := self.Yours.ToJSON()
+		message = fmt.Sprintf("%s\nyours:  %s", message, yours)
+	}
+
+	return fmt.Sprintf("%s\ntheirs: %s", message, theirs)
+}
+
+func (self ConflictError) Unwrap() error {
+
+	return ErrConflict
+}
+
+// CheckRevision returns the stored task with this id.
+// It returns a ConflictError when the stored revision isn't the one that was read.
+// The store should be locked so nothing changes between the check and the save.
+func CheckRevision(store TaskStore, id string, revision int) (Task, error) {
+
+	latest, error := store.Get(id)
+
+	if error != nil {
+		return latest, error
+	}
+
+	if latest.revision != revision {
+		return latest, ConflictError{Revision: revision, Theirs: latest}
+	}
+
+	return latest, nil
+}
+
+type mergeableField struct {
+	name  string
+	equal func(a, b Task) bool
+	take  func(to *Task, from Task)
+}
+
+var mergeableFields = []mergeableField{
+	{
+		name:  "title",
+		equal: func(a, b Task) bool { return a.Title == b.Title },
+		take:  func(to *Task, from Task) { to.Title = from.Title },
+	},
+	{
+		name:  "description",
+		equal: func(a, b Task) bool { return a.Description == b.Description },
+		take:  func(to *Task, from Task) { to.Description = from.Description },
+	},
+	{
+		name:  "priority",
+		equal: func(a, b Task) bool { return a.Priority == b.Priority },
+		take:  func(to *Task, from Task) { to.Priority = from.Priority },
+	},
+	{
+		name:  "status",
+		equal: func(a, b Task) bool { return a.Status == b.Status },
+		take:  func(to *Task, from Task) { to.Status = from.Status },
+	},
+	{
+		name:  "due",
+		equal: func(a, b Task) bool { return a.DueTimeStamp() == b.DueTimeStamp() },
+		take:  func(to *Task, from Task) { to.Due = from.Due },
+	},
+	{
+		name:  "tags",
+		equal: func(a, b Task) bool { return slices.Equal(a.Tags, b.Tags) },
+		take:  func(to *Task, from Task) { to.Tags = from.Tags },
+	},
+	{
+		name:  "blockedBy",
+		equal: func(a, b Task) bool { return slices.Equal(a.BlockedBy, b.BlockedBy) },
+		take:  func(to *Task, from Task) { to.BlockedBy = from.BlockedBy },
+	},
+	{
+		name:  "repeat",
+		equal: func(a, b Task) bool { return formatRecurrence(a.Repeat) == formatRecurrence(b.Repeat) },
+		take:  func(to *Task, from Task) { to.Repeat = from.Repeat },
+	},
+}
+
+// Merge does a three way merge of the fields that can be edited.
+// Fields only one side changed are taken from that side.
+// Fields both sides changed differently are returned as conflicts
+// and keep theirs until TakeField picks a side.
+func Merge(base, yours, theirs Task) (Task, []string) {
+
+	merged := theirs
+
+	conflicts := []string{}
+
+	for _, field := range mergeableFields {
+
+		yoursChanged := !field.equal(base, yours)
+		theirsChanged := !field.equal(base, theirs)
+
+		switch {
+		case yoursChanged && theirsChanged && !field.equal(yours, theirs):
+			conflicts = append(conflicts, field.name)
+		case yoursChanged:
+			field.take(&merged, yours)
+		}
+	}
+
+	merged.UpdatedAt = time.Now()
+
+	return merged.Rebase(theirs), conflicts
+}
+
+// TakeField copies one mergeable field from a task.
+func (self *Task) TakeField(name string, from Task) {
+
+	field, ok := lo.Find(mergeableFields, func(item mergeableField) bool {
+		return item.name == name
+	})
+
+	if ok {
+		field.take(self, from)
+	}
+}
+
+// FieldValue formats a mergeable field so it can be shown to a user.
+func (self Task) FieldValue(name string) string {
+
+	switch name {
+	case "title":
+		return self.Title
+	case "description":
+		return self.Description
+	case "priority":
+		return self.Priority.Value()
+	case "status":
+		return self.Status.Value()
+	case "due":
+		if self.Due == nil {
+			return "none"
+		}
+		return self.Due.Format(time.RFC3339)
+	case "tags":
+		return strings.Join(self.Tags, ", ")
+	case "blockedBy":
+		return strings.Join(self.BlockedBy, ", ")
+	case "repeat":
+		if self.Repeat == nil {
+			return "none"
+		}
+		return self.Repeat.Describe()
+	}
+
+	return ""
+}
@@ -0,0 +1,392 @@
+package task
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mini-clis/task-list/dates"
+	"github.com/samber/lo"
+)
+
+const CSV = "csv"
+
+var ErrUnknownColumn = errors.New("isn't a column")
+
+var ErrInvalidRows = errors.New("rows couldn't be imported")
+
+// RowError is why a cell or a whole row of a CSV file couldn't be imported.
+// Row is the line the row starts on so the header is row 1 like in a spreadsheet.
+// Column is empty when the whole row is wrong.
+type RowError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (self RowError) Error() string {
+
+	if self.Column == "" {
+		return fmt.Sprintf("row %d: %s", self.Row, self.Err)
+	}
+
+	return fmt.Sprintf("row %d %s: %s", self.Row, self.Column, self.Err)
+}
+
+func (self RowError) Unwrap() error {
+
+	return self.Err
+}
+
+// InvalidRowsError is returned after the rows that could be imported were.
+// It lists every problem on its own line.
+type InvalidRowsError struct {
+	Imported int
+	Errors   []RowError
+}
+
+func (self InvalidRowsError) Error() string {
+
+	invalidRows := len(lo.UniqBy(self.Errors, func(item RowError) int { return item.Row }))
+
+	lines := lo.Map(self.Errors, func(item RowError, index int) string { return "  " + item.Error() })
+
+	return fmt.Sprintf("%d of %d %s\n%s", invalidRows, self.Imported+invalidRows, ErrInvalidRows, strings.Join(lines, "\n"))
+}
+
+func (self InvalidRowsError) Unwrap() error {
+
+	return ErrInvalidRows
+}
+
+// csvColumn is a task field in a CSV file.
+// set is nil for fields like id that a new task can't take from a file.
+type csvColumn struct {
+	name  string
+	value func(task Task) string
+	set   func(task *Task, cell string) error
+}
+
+func formatCSVTime(moment time.Time) string {
+
+	return moment.In(time.Local).Format(time.RFC3339)
+}
+
+func parseCSVTime(cell string) (time.Time, error) {
+
+	return dates.Parse(cell)
+}
+
+// splitCSVList reads the comma separated lists tags and blockedBy are written as.
+func splitCSVList(cell string) []string {
+
+	return lo.Compact(lo.Map(strings.Split(cell, ","), func(item string, index int) string {
+		return strings.TrimSpace(item)
+	}))
+}
+
+var csvColumns = []csvColumn{
+	{name: "id", value: func(task Task) string { return task.id }},
+	{
+		name:  "title",
+		value: func(task Task) string { return task.Title },
+		set: func(task *Task, cell string) error {
+			task.Title = cell
+			return nil
+		},
+	},
+	{
+		name:  "description",
+		value: func(task Task) string { return task.Description },
+		set: func(task *Task, cell string) error {
+			task.Description = cell
+			return nil
+		},
+	},
+	{
+		name:  "priority",
+		value: func(task Task) string { return task.Priority.Value() },
+		set: func(task *Task, cell string) error {
+			parsedPriority, error := ParsePriority(strings.ToLower(cell))
+			task.Priority = lo.Ternary(error == nil, parsedPriority, task.Priority)
+			return error
+		},
+	},
+	{
+		name:  "status",
+		value: func(task Task) string { return task.Status.Value() },
+		set: func(task *Task, cell string) error {
+			status, error := parseStoredStatus(cell)
+			task.Status = lo.Ternary(error == nil, status, task.Status)
+			return error
+		},
+	},
+	{
+		name:  "complete",
+		value: func(task Task) string { return strconv.FormatBool(task.Done()) },
+		set: func(task *Task, cell string) error {
+			complete, error := strconv.ParseBool(cell)
+			*task = task.MarkComplete(complete)
+			return error
+		},
+	},
+	{
+		name:  "createdAt",
+		value: func(task Task) string { return formatCSVTime(time.UnixMicro(task.createdAt)) },
+		set: func(task *Task, cell string) error {
+			createdAt, error := parseCSVTime(cell)
+			task.createdAt = lo.Ternary(error == nil, createdAt.UnixMicro(), task.createdAt)
+			return error
+		},
+	},
+	{
+		name:  "updatedAt",
+		value: func(task Task) string { return formatCSVTime(task.UpdatedAt) },
+		set: func(task *Task, cell string) error {
+			updatedAt, error := parseCSVTime(cell)
+			task.UpdatedAt = lo.Ternary(error == nil, updatedAt, task.UpdatedAt)
+			return error
+		},
+	},
+	{name: "revision", value: func(task Task) string { return strconv.Itoa(task.revision) }},
+	{
+		name: "due",
+		value: func(task Task) string {
+			return lo.TernaryF(task.Due == nil, func() string { return "" }, func() string { return formatCSVTime(*task.Due) })
+		},
+		set: func(task *Task, cell string) error {
+			due, error := parseCSVTime(cell)
+			task.Due = lo.Ternary(error == nil, &due, nil)
+			return error
+		},
+	},
+	{
+		name:  "tags",
+		value: func(task Task) string { return strings.Join(task.Tags, ",") },
+		set: func(task *Task, cell string) error {
+			tags, error := ParseTags(splitCSVList(cell))
+			task.Tags = tags
+			return error
+		},
+	},
+	{name: "parentId", value: func(task Task) string { return task.ParentId }},
+	{name: "blockedBy", value: func(task Task) string { return strings.Join(task.BlockedBy, ",") }},
+	{
+		name:  "repeat",
+		value: func(task Task) string { return formatRecurrence(task.Repeat) },
+		set: func(task *Task, cell string) error {
+			recurrence, error := ParseRecurrence(cell)
+			task.Repeat = lo.Ternary(error == nil, &recurrence, nil)
+			return error
+		},
+	},
+	{name: "list", value: func(task Task) string { return task.List }},
+	{name: "number", value: func(task Task) string { return strconv.Itoa(task.number) }},
+	{
+		name: "metadata",
+		value: func(task Task) string {
+			metadata := task.UserMetadata()
+			if len(metadata) == 0 {
+				return ""
+			}
+			byte, _ := json.Marshal(metadata)
+			return string(byte)
+		},
+		set: func(task *Task, cell string) error {
+			return json.Unmarshal([]byte(cell), &task.Metadata)
+		},
+	},
+}
+
+// CSVColumns is every column a CSV export can have in the order they're written by default.
+func CSVColumns() []string {
+
+	return lo.Map(csvColumns, func(column csvColumn, index int) string { return column.name })
+}
+
+func findCSVColumn(name string) (csvColumn, bool) {
+
+	return lo.Find(csvColumns, func(column csvColumn) bool { return strings.EqualFold(column.name, name) })
+}
+
+// ParseCSVColumns checks every name is a column and returns them spelled the way they're written.
+func ParseCSVColumns(names []string) ([]string, error) {
+
+	columns := []string{}
+
+	for _, name := range names {
+
+		column, ok := findCSVColumn(strings.TrimSpace(name))
+
+		if !ok {
+			return nil, fmt.Errorf("%q %w try one of %s", name, ErrUnknownColumn, strings.Join(CSVColumns(), ", "))
+		}
+
+		columns = append(columns, column.name)
+	}
+
+	return lo.Uniq(columns), nil
+}
+
+// ParseCSVMapping reads header=field pairs into the field each header fills.
+// Only the fields a new task can take from a file can be mapped to.
+func ParseCSVMapping(inputs []string) (map[string]string, error) {
+
+	mapping := map[string]string{}
+
+	for _, input := range inputs {
+
+		header, name, ok := strings.Cut(input, "=")
+
+		if !ok || strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("%q should look like header=field", input)
+		}
+
+		column, ok := findCSVColumn(strings.TrimSpace(name))
+
+		if !ok || column.set == nil {
+			return nil, fmt.Errorf(
+				"%q %w that can be imported try one of %s",
+				name,
+				ErrUnknownColumn,
+				strings.Join(importableCSVColumns(), ", "),
+			)
+		}
+
+		mapping[strings.TrimSpace(header)] = column.name
+	}
+
+	return mapping, nil
+}
+
+func importableCSVColumns() []string {
+
+	return lo.FilterMap(csvColumns, func(column csvColumn, index int) (string, bool) {
+		return column.name, column.set != nil
+	})
+}
+
+// WriteCSV writes a header and a row for every task with the columns in order.
+func WriteCSV(writer io.Writer, tasks []Task, columns []string) error {
+
+	selected := lo.Map(columns, func(name string, index int) csvColumn {
+		column, _ := findCSVColumn(name)
+		return column
+	})
+
+	csvWriter := csv.NewWriter(writer)
+
+	if error := csvWriter.Write(columns); error != nil {
+		return error
+	}
+
+	for _, item := range tasks {
+
+		row := lo.Map(selected, func(column csvColumn, index int) string { return column.value(item) })
+
+		if error := csvWriter.Write(row); error != nil {
+			return error
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// ReadCSV reads a task from every row of a CSV file with a header into list.
+// Headers are matched to fields by name unless mapping says which field they fill
+// and headers that don't match a field are skipped.
+// A row with a cell that isn't valid is left out and every problem with it is returned
+// so one bad row doesn't stop the others being imported.
+func ReadCSV(reader io.Reader, list string, mapping map[string]string) ([]Task, []RowError, error) {
+
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, error := csvReader.Read()
+
+	if errors.Is(error, io.EOF) {
+		return []Task{}, nil, nil
+	}
+
+	if error != nil {
+		return nil, nil, error
+	}
+
+	columns := lo.Map(header, func(name string, index int) *csvColumn {
+
+		if field, ok := mapping[strings.TrimSpace(name)]; ok {
+			name = field
+		}
+
+		column, ok := findCSVColumn(strings.TrimSpace(name))
+
+		return lo.Ternary(ok && column.set != nil, &column, nil)
+	})
+
+	if !lo.ContainsBy(columns, func(column *csvColumn) bool { return column != nil && column.name == "title" }) {
+		return nil, nil, fmt.Errorf("There's no title column map one with --map header=title")
+	}
+
+	tasks := []Task{}
+	rowErrors := []RowError{}
+
+	for {
+
+		record, error := csvReader.Read()
+
+		if errors.Is(error, io.EOF) {
+			break
+		}
+
+		var parseError *csv.ParseError
+
+		if errors.As(error, &parseError) {
+			rowErrors = append(rowErrors, RowError{Row: parseError.StartLine, Err: parseError.Err})
+			continue
+		}
+
+		if error != nil {
+			return nil, nil, error
+		}
+
+		row, _ := csvReader.FieldPos(0)
+
+		item := NewTask("", "")
+		item.List = list
+
+		cellErrors := []RowError{}
+
+		for index, cell := range record {
+
+			column := columns[index]
+
+			if column == nil || strings.TrimSpace(cell) == "" {
+				continue
+			}
+
+			if error := column.set(&item, strings.TrimSpace(cell)); error != nil {
+				cellErrors = append(cellErrors, RowError{Row: row, Column: column.name, Err: error})
+			}
+		}
+
+		if strings.TrimSpace(item.Title) == "" {
+			cellErrors = append(cellErrors, RowError{Row: row, Column: "title", Err: errors.New("A task needs a title")})
+		}
+
+		if len(cellErrors) > 0 {
+			rowErrors = append(rowErrors, cellErrors...)
+			continue
+		}
+
+		tasks = append(tasks, item)
+	}
+
+	return tasks, rowErrors, nil
+}
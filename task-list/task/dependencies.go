@@ -0,0 +1,142 @@
+package task
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var ErrCycle = errors.New("Tasks can't block each other in a cycle")
+
+// CycleError names the tasks that block each other in the order they were found.
+// The first id is repeated at the end to close the loop.
+type CycleError struct {
+	Ids []string
+}
+
+func (self CycleError) Error() string {
+
+	return fmt.Sprintf("%s %s", ErrCycle, strings.Join(self.Ids, " -> "))
+}
+
+func (self CycleError) Unwrap() error {
+
+	return ErrCycle
+}
+
+// AddBlockers skips ids the task is already blocked by.
+func (self Task) AddBlockers(ids ...string) []string {
+
+	return lo.Uniq(append(slices.Clone(self.BlockedBy), ids...))
+}
+
+func (self Task) RemoveBlockers(ids ...string) []string {
+
+	return lo.Without(self.BlockedBy, ids...)
+}
+
+// ValidateDependencies returns a CycleError for the first cycle it finds.
+// Ids of tasks that don't exist anymore are ignored.
+func ValidateDependencies(tasks []Task) error {
+
+	blockedBy := lo.SliceToMap(tasks, func(item Task) (string, []string) {
+		return item.id, item.BlockedBy
+	})
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	states := map[string]int{}
+	path := []string{}
+
+	var visit func(id string) error
+
+	visit = func(id string) error {
+
+		switch states[id] {
+		case visiting:
+			start := slices.Index(path, id)
+			return CycleError{Ids: append(slices.Clone(path[start:]), id)}
+		case visited:
+			return nil
+		}
+
+		states[id] = visiting
+		path = append(path, id)
+
+		for _, blockerId := range blockedBy[id] {
+
+			if _, ok := blockedBy[blockerId]; !ok {
+				continue
+			}
+
+			if error := visit(blockerId); error != nil {
+				return error
+			}
+		}
+
+		path = path[:len(path)-1]
+		states[id] = visited
+
+		return nil
+	}
+
+	for _, item := range tasks {
+
+		if error := visit(item.id); error != nil {
+			return error
+		}
+	}
+
+	return nil
+}
+
+// Blockers are the incomplete tasks a task is waiting on.
+func (self Task) Blockers(tasks []Task) []Task {
+
+	return lo.Filter(tasks, func(item Task, index int) bool {
+		return !item.Done() && lo.Contains(self.BlockedBy, item.id)
+	})
+}
+
+func (self Task) Blocked(tasks []Task) bool {
+
+	return len(self.Blockers(tasks)) > 0
+}
+
+// Next returns the incomplete tasks that aren't blocked.
+// The highest priority comes first then the soonest due date.
+// Tasks without a due date come after the ones with one.
+func Next(tasks []Task) []Task {
+
+	next := lo.Filter(tasks, func(item Task, index int) bool {
+		return !item.Done() && !item.Blocked(tasks)
+	})
+
+	slices.SortStableFunc(next, func(a, b Task) int {
+
+		if order := b.Priority.Order() - a.Priority.Order(); order != 0 {
+			return order
+		}
+
+		switch {
+		case a.Due == nil && b.Due == nil:
+			return cmp.Compare(a.createdAt, b.createdAt)
+		case a.Due == nil:
+			return 1
+		case b.Due == nil:
+			return -1
+		}
+
+		return a.Due.Compare(*b.Due)
+	})
+
+	return next
+}
@@ -0,0 +1,230 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var ErrOpenChildren = errors.New("A task can't be completed while its subtasks are open")
+
+// CompletionRule decides what happens to subtasks when their parent is completed.
+type CompletionRule string
+
+// FREE lets a parent be completed whatever state its subtasks are in.
+const FREE = CompletionRule("free")
+
+// BLOCK refuses to complete a parent while any of its subtasks are open.
+const BLOCK = CompletionRule("block")
+
+// CASCADE completes every open subtask along with the parent.
+const CASCADE = CompletionRule("cascade")
+
+var AllowedCompletionRules = []string{
+	string(FREE),
+	string(BLOCK),
+	string(CASCADE),
+}
+
+// ParseCompletionRule treats an empty input as FREE.
+func ParseCompletionRule(input string) (CompletionRule, error) {
+
+	if input == "" {
+		return FREE, nil
+	}
+
+	if !lo.Contains(AllowedCompletionRules, input) {
+
+		return "", fmt.Errorf(
+			"Wrong option %s a parent completion rule is supposed to be %s",
+			input,
+			strings.Join(AllowedCompletionRules, ","),
+		)
+	}
+
+	return CompletionRule(input), nil
+}
+
+func Children(tasks []Task, id string) []Task {
+
+	return lo.Filter(tasks, func(item Task, index int) bool {
+		return item.ParentId == id
+	})
+}
+
+// Descendants walks down from a task depth first.
+// A hand edited file could have a parent loop so every task is visited once.
+func Descendants(tasks []Task, id string) []Task {
+
+	descendants := []Task{}
+
+	visited := map[string]bool{id: true}
+
+	var walk func(parentId string)
+
+	walk = func(parentId string) {
+
+		for _, child := range Children(tasks, parentId) {
+
+			if visited[child.id] {
+				continue
+			}
+
+			visited[child.id] = true
+			descendants = append(descendants, child)
+			walk(child.id)
+		}
+	}
+
+	walk(id)
+
+	return descendants
+}
+
+// CompleteParent applies the completion rule to a task that is being completed.
+// It returns the subtasks that were completed along with it.
+func CompleteParent(tasks []Task, id string, rule CompletionRule) ([]Task, error) {
+
+	openDescendants := lo.Filter(Descendants(tasks, id), func(item Task, index int) bool {
+		return !item.Done()
+	})
+
+	if len(openDescendants) == 0 {
+		return nil, nil
+	}
+
+	switch rule {
+	case BLOCK:
+		return nil, fmt.Errorf(
+			"%w %d subtasks of %s are open",
+			ErrOpenChildren,
+			len(openDescendants),
+			id,
+		)
+	case CASCADE:
+		return lo.Map(openDescendants, func(item Task, index int) Task {
+			item.Status = DONE
+			return item.Revise()
+		}), nil
+	}
+
+	return nil, nil
+}
+
+// Reparent moves the children of a deleted task up to the deleted task's parent.
+// When that parent is also being deleted it keeps climbing.
+func Reparent(tasks []Task, deletedIds []string) []Task {
+
+	parents := lo.SliceToMap(tasks, func(item Task) (string, string) {
+		return item.id, item.ParentId
+	})
+
+	survivingParent := func(id string) string {
+
+		for id != "" && lo.Contains(deletedIds, id) {
+			id = parents[id]
+		}
+
+		return id
+	}
+
+	return lo.FilterMap(tasks, func(item Task, index int) (Task, bool) {
+
+		if lo.Contains(deletedIds, item.id) || !lo.Contains(deletedIds, item.ParentId) {
+			return item, false
+		}
+
+		item.ParentId = survivingParent(item.ParentId)
+
+		return item.Revise(), true
+	})
+}
+
+// TreeNode is a task along with its subtasks.
+type TreeNode struct {
+	Task     Task
+	Children []TreeNode
+}
+
+// BuildTree keeps the order of tasks.
+// Tasks whose parent isn't in the list become roots.
+func BuildTree(tasks []Task) []TreeNode {
+
+	ids := lo.SliceToMap(tasks, func(item Task) (string, bool) {
+		return item.id, true
+	})
+
+	var build func(parentId string) []TreeNode
+
+	build = func(parentId string) []TreeNode {
+
+		return lo.FilterMap(tasks, func(item Task, index int) (TreeNode, bool) {
+
+			isRoot := item.ParentId == "" || !ids[item.ParentId]
+
+			if parentId == "" && !isRoot || parentId != "" && item.ParentId != parentId {
+				return TreeNode{}, false
+			}
+
+			return TreeNode{Task: item, Children: build(item.id)}, true
+		})
+	}
+
+	return build("")
+}
+
+// Counts rolls up how many subtasks are done out of all of them at every depth.
+func (self TreeNode) Counts() (int, int) {
+
+	done, total := 0, 0
+
+	for _, child := range self.Children {
+
+		childDone, childTotal := child.Counts()
+
+		done += childDone + lo.Ternary(child.Task.Done(), 1, 0)
+		total += childTotal + 1
+	}
+
+	return done, total
+}
+
+type persistedTreeNode struct {
+	persistedTask
+	Done     int                 `json:"done"`
+	Total    int                 `json:"total"`
+	Children []persistedTreeNode `json:"children"`
+}
+
+func (self TreeNode) toPersistedTreeNode() persistedTreeNode {
+
+	done, total := self.Counts()
+
+	return persistedTreeNode{
+		persistedTask: self.Task.toPersistedTask(),
+		Done:          done,
+		Total:         total,
+		Children: lo.Map(self.Children, func(item TreeNode, index int) persistedTreeNode {
+			return item.toPersistedTreeNode()
+		}),
+	}
+}
+
+// MarshallTree nests subtasks under children and adds their rolled up counts.
+func MarshallTree(nodes []TreeNode) (string, error) {
+
+	persistedNodes := lo.Map(nodes, func(item TreeNode, index int) persistedTreeNode {
+		return item.toPersistedTreeNode()
+	})
+
+	byte, error := json.Marshal(&persistedNodes)
+
+	if error != nil {
+		return "", error
+	}
+
+	return string(byte), nil
+}
@@ -0,0 +1,226 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	ADDED   = "added"
+	EDITED  = "edited"
+	DELETED = "deleted"
+	UNDONE  = "undone"
+	REDONE  = "redone"
+)
+
+// FieldChange is one field of a task going from Old to New.
+// A field that wasn't set is empty.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// HistoryEntry is what one command did to a task.
+// Kind is added, edited or deleted for a command and undone or redone
+// when the changes of Command were taken back or made again.
+type HistoryEntry struct {
+	Time      time.Time
+	Command   string
+	Operation string
+	Kind      string
+	Changes   []FieldChange
+}
+
+type historyField struct {
+	name  string
+	value func(task Task) string
+}
+
+var historyFields = []historyField{
+	{name: "title", value: func(task Task) string { return task.Title }},
+	{name: "description", value: func(task Task) string { return task.Description }},
+	{name: "priority", value: func(task Task) string { return task.Priority.Value() }},
+	{name: "status", value: func(task Task) string { return task.Status.Value() }},
+	{name: "due", value: func(task Task) string {
+		return lo.TernaryF(task.Due == nil, func() string { return "" }, func() string { return task.Due.Format(time.RFC3339) })
+	}},
+	{name: "tags", value: func(task Task) string { return strings.Join(task.Tags, ", ") }},
+	{name: "blockedBy", value: func(task Task) string { return strings.Join(task.BlockedBy, ", ") }},
+	{name: "repeat", value: func(task Task) string { return formatRecurrence(task.Repeat) }},
+	{name: "list", value: func(task Task) string { return task.List }},
+	{name: "parentId", value: func(task Task) string { return task.ParentId }},
+	{name: "metadata", value: func(task Task) string {
+		return strings.Join(lo.Map(slices.Sorted(maps.Keys(task.Metadata)), func(key string, index int) string {
+			return key + "=" + task.Metadata[key]
+		}), ", ")
+	}},
+}
+
+// diffFields lists the fields that differ between two versions of a task.
+// A missing side counts as every field being empty.
+func diffFields(before, after *Task) []FieldChange {
+
+	value := func(task *Task, field historyField) string {
+		return lo.TernaryF(task == nil, func() string { return "" }, func() string { return field.value(*task) })
+	}
+
+	return lo.FilterMap(historyFields, func(field historyField, index int) (FieldChange, bool) {
+
+		change := FieldChange{Field: field.name, Old: value(before, field), New: value(after, field)}
+
+		return change, change.Old != change.New
+	})
+}
+
+// History is every change made to the task with this id oldest first.
+// Several changes to the task in one command are one entry going from the first version to the last.
+// Commands that only changed bookkeeping like the revision are left out.
+func (self Journal) History(id string) ([]HistoryEntry, error) {
+
+	records, error := self.read()
+
+	if error != nil {
+		return nil, error
+	}
+
+	entries := []HistoryEntry{}
+	changes := map[string]*Change{}
+	commands := map[string]string{}
+	indexes := map[string]int{}
+
+	for _, record := range records {
+
+		switch record.Kind {
+		case changeRecord:
+
+			before, beforeError := decodeSnapshot(record.SchemaVersion, record.Before)
+			after, afterError := decodeSnapshot(record.SchemaVersion, record.After)
+
+			if error := errors.Join(beforeError, afterError); error != nil {
+				return nil, error
+			}
+
+			if (Change{Before: before, After: after}).Id() != id {
+				continue
+			}
+
+			change, ok := changes[record.Operation]
+
+			if !ok {
+				change = &Change{Before: before}
+				changes[record.Operation] = change
+				commands[record.Operation] = record.Command
+				indexes[record.Operation] = -1
+			}
+
+			change.After = after
+
+			entry := HistoryEntry{
+				Time:      time.UnixMicro(record.Time),
+				Command:   record.Command,
+				Operation: record.Operation,
+				Kind: lo.If(change.Before == nil, ADDED).
+					ElseIf(change.After == nil, DELETED).
+					Else(EDITED),
+				Changes: diffFields(change.Before, change.After),
+			}
+
+			switch {
+			case indexes[record.Operation] >= 0:
+				entries[indexes[record.Operation]] = entry
+			case len(entry.Changes) > 0:
+				indexes[record.Operation] = len(entries)
+				entries = append(entries, entry)
+			}
+
+		case undoRecord, redoRecord:
+
+			change, ok := changes[record.Operation]
+
+			if !ok {
+				continue
+			}
+
+			entry := HistoryEntry{
+				Time:      time.UnixMicro(record.Time),
+				Command:   commands[record.Operation],
+				Operation: record.Operation,
+				Kind:      lo.Ternary(record.Kind == undoRecord, UNDONE, REDONE),
+				Changes: lo.Ternary(
+					record.Kind == undoRecord,
+					diffFields(change.After, change.Before),
+					diffFields(change.Before, change.After),
+				),
+			}
+
+			if len(entry.Changes) > 0 {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// Tasks is the last version the journal saw of every task it has a change for
+// so tasks that were deleted can still be found.
+func (self Journal) Tasks() ([]Task, error) {
+
+	operations, error := self.Operations()
+
+	if error != nil {
+		return nil, error
+	}
+
+	tasks := map[string]Task{}
+	order := []string{}
+
+	for _, operation := range operations {
+		for _, change := range operation.Changes {
+
+			if _, ok := tasks[change.Id()]; !ok {
+				order = append(order, change.Id())
+			}
+
+			tasks[change.Id()] = *lo.Ternary(change.After != nil, change.After, change.Before)
+		}
+	}
+
+	return lo.Map(order, func(id string, index int) Task { return tasks[id] }), nil
+}
+
+type persistedHistoryEntry struct {
+	Time      int64         `json:"time"`
+	Command   string        `json:"command"`
+	Operation string        `json:"operation"`
+	Kind      string        `json:"kind"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+func MarshallHistory(entries []HistoryEntry) (string, error) {
+
+	persistedEntries := lo.Map(entries, func(entry HistoryEntry, index int) persistedHistoryEntry {
+		return persistedHistoryEntry{
+			Time:      entry.Time.UnixMicro(),
+			Command:   entry.Command,
+			Operation: entry.Operation,
+			Kind:      entry.Kind,
+			Changes:   entry.Changes,
+		}
+	})
+
+	byte, error := json.Marshal(&persistedEntries)
+
+	if error != nil {
+		return "", error
+	}
+
+	return string(byte), nil
+}
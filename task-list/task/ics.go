@@ -0,0 +1,446 @@
+package task
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/samber/lo"
+)
+
+const ICS = "ics"
+
+var ErrInvalidICS = errors.New("isn't valid iCalendar")
+
+// The UID a calendar app gave a to-do is kept so exporting it again updates the same to-do.
+const icsUidKey = "ics:uid"
+
+const (
+	icsProductId = "-//mini-clis//task-list//EN"
+	icsDateTime  = "20060102T150405Z"
+	icsDate      = "20060102"
+	// Lines longer than this many bytes are folded onto the next line.
+	icsLineLength = 75
+)
+
+// RFC 5545 priorities go from 1 for the highest to 9 for the lowest.
+var icsPriorities = map[priority]int{
+	HIGH:   1,
+	MEDIUM: 5,
+	LOW:    9,
+}
+
+// icsRepeatProperty keeps rules that can't be an RRULE like ones counted from completion.
+const icsRepeatProperty = "X-TASK-LIST-REPEAT"
+
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func escapeICSText(text string) string {
+
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// splitICSText splits a list of values on the commas that aren't escaped and unescapes each one.
+func splitICSText(value string) []string {
+
+	values := []string{}
+
+	var current strings.Builder
+
+	for index := 0; index < len(value); index++ {
+
+		switch character := value[index]; {
+		case character == '\\' && index+1 < len(value):
+			index++
+			current.WriteByte(lo.Ternary(value[index] == 'n' || value[index] == 'N', '\n', value[index]))
+		case character == ',':
+			values = append(values, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(character)
+		}
+	}
+
+	return append(values, current.String())
+}
+
+func unescapeICSText(value string) string {
+
+	return strings.Join(splitICSText(value), ",")
+}
+
+// foldICSLine breaks a content line into lines of at most 75 bytes
+// without splitting a character, starting each continuation with a space.
+func foldICSLine(line string) string {
+
+	var folded strings.Builder
+
+	limit := icsLineLength
+
+	for len(line) > limit {
+
+		end := limit
+
+		for end > 0 && !utf8.RuneStart(line[end]) {
+			end--
+		}
+
+		folded.WriteString(line[:end] + "\r\n ")
+		line = line[end:]
+		limit = icsLineLength - 1
+	}
+
+	folded.WriteString(line + "\r\n")
+
+	return folded.String()
+}
+
+func formatICSTime(moment time.Time) string {
+
+	return moment.UTC().Format(icsDateTime)
+}
+
+func isICSDate(moment time.Time) bool {
+
+	local := moment.In(time.Local)
+
+	return local.Hour() == 0 && local.Minute() == 0 && local.Second() == 0
+}
+
+// icsUid is the UID a task is exported with.
+func (self Task) icsUid() string {
+
+	return lo.CoalesceOrEmpty(self.Metadata[icsUidKey], self.id)
+}
+
+func (self Task) icsLines(tasks []Task) []string {
+
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + escapeICSText(self.icsUid()),
+		"DTSTAMP:" + formatICSTime(self.UpdatedAt),
+		"CREATED:" + formatICSTime(time.UnixMicro(self.createdAt)),
+		"LAST-MODIFIED:" + formatICSTime(self.UpdatedAt),
+		"SUMMARY:" + escapeICSText(self.Title),
+	}
+
+	if self.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICSText(self.Description))
+	}
+
+	lines = append(lines, fmt.Sprintf("PRIORITY:%d", icsPriorities[self.Priority]))
+
+	switch self.Status {
+	case DONE:
+		lines = append(lines, "STATUS:COMPLETED", "COMPLETED:"+formatICSTime(self.UpdatedAt))
+	case DOING:
+		lines = append(lines, "STATUS:IN-PROCESS")
+	default:
+		lines = append(lines, "STATUS:NEEDS-ACTION")
+	}
+
+	if self.Due != nil {
+		lines = append(lines, lo.TernaryF(
+			isICSDate(*self.Due),
+			func() string { return "DUE;VALUE=DATE:" + self.Due.In(time.Local).Format(icsDate) },
+			func() string { return "DUE:" + formatICSTime(*self.Due) },
+		))
+	}
+
+	if len(self.Tags) > 0 {
+		lines = append(lines, "CATEGORIES:"+strings.Join(lo.Map(self.Tags, func(tag string, index int) string {
+			return escapeICSText(tag)
+		}), ","))
+	}
+
+	if self.Repeat != nil {
+		lines = append(lines, lo.Ternary(self.Repeat.AfterCompletion, icsRepeatProperty, "RRULE")+":"+self.Repeat.String())
+	}
+
+	if parent, error := findTask(tasks, self.ParentId); self.ParentId != "" && error == nil {
+		lines = append(lines, "RELATED-TO:"+escapeICSText(parent.icsUid()))
+	}
+
+	return append(lines, "END:VTODO")
+}
+
+// WriteICS writes the tasks as the VTODO components of one calendar
+// with CRLF line endings and long lines folded like RFC 5545 says.
+func WriteICS(writer io.Writer, tasks []Task) error {
+
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:" + icsProductId}
+
+	for _, item := range tasks {
+		lines = append(lines, item.icsLines(tasks)...)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+
+		if _, error := io.WriteString(writer, foldICSLine(line)); error != nil {
+			return error
+		}
+	}
+
+	return nil
+}
+
+// parseICSProperty splits a content line like DUE;TZID=Europe/Paris:20250601T170000
+// into its name, parameters and value. Parameter values can be quoted to hold a colon.
+func parseICSProperty(line string) (icsProperty, bool) {
+
+	quoted := false
+
+	colon := strings.IndexFunc(line, func(character rune) bool {
+		quoted = quoted != (character == '"')
+		return character == ':' && !quoted
+	})
+
+	if colon <= 0 {
+		return icsProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+
+	property := icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+
+	for _, part := range parts[1:] {
+
+		name, value, _ := strings.Cut(part, "=")
+
+		property.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+
+	return property, true
+}
+
+// unfoldICS joins folded lines back up and drops empty ones.
+// It returns the line each content line started on so errors can point at it.
+func unfoldICS(reader io.Reader) ([]string, []int, error) {
+
+	lines, starts := []string{}, []int{}
+
+	scanner := bufio.NewScanner(reader)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for number := 1; scanner.Scan(); number++ {
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+		starts = append(starts, number)
+	}
+
+	return lines, starts, scanner.Err()
+}
+
+func parseICSTime(property icsProperty) (time.Time, error) {
+
+	if property.params["VALUE"] == "DATE" || len(property.value) == len(icsDate) {
+		return time.ParseInLocation(icsDate, property.value, time.Local)
+	}
+
+	if strings.HasSuffix(property.value, "Z") {
+		return time.Parse(icsDateTime, property.value)
+	}
+
+	location := time.Local
+
+	if tzid, ok := property.params["TZID"]; ok {
+		if loaded, error := time.LoadLocation(tzid); error == nil {
+			location = loaded
+		}
+	}
+
+	return time.ParseInLocation(strings.TrimSuffix(icsDateTime, "Z"), property.value, location)
+}
+
+func icsPriority(value string) priority {
+
+	level, _ := strconv.Atoi(value)
+
+	switch {
+	case level >= 1 && level <= 4:
+		return HIGH
+	case level == 5:
+		return MEDIUM
+	}
+
+	return LOW
+}
+
+type icsTodo struct {
+	task      Task
+	parentUid string
+}
+
+// readICSTodo fills a task from the properties of one VTODO.
+// Properties task-list has no field for are skipped.
+func readICSTodo(properties []icsProperty, list string) (icsTodo, error) {
+
+	todo := icsTodo{task: NewTask("", "")}
+	todo.task.List = list
+
+	var stamped, lastModified, completed, created *time.Time
+
+	for _, property := range properties {
+
+		switch property.name {
+		case "UID":
+			todo.task.Metadata = map[string]string{icsUidKey: unescapeICSText(property.value)}
+		case "SUMMARY":
+			todo.task.Title = unescapeICSText(property.value)
+		case "DESCRIPTION":
+			todo.task.Description = unescapeICSText(property.value)
+		case "PRIORITY":
+			todo.task.Priority = icsPriority(property.value)
+		case "STATUS":
+			switch {
+			case todo.task.Done():
+			case strings.EqualFold(property.value, "COMPLETED"):
+				todo.task.Status = DONE
+			case strings.EqualFold(property.value, "IN-PROCESS"):
+				todo.task.Status = DOING
+			}
+		case "CATEGORIES":
+			// Categories that can't be a tag like ones with a space are skipped.
+			todo.task.Tags = todo.task.AddTags(lo.FilterMap(splitICSText(property.value), func(category string, index int) (string, bool) {
+				tag, error := ParseTag(category)
+				return tag, error == nil
+			})...)
+		case "RELATED-TO":
+			if lo.CoalesceOrEmpty(strings.ToUpper(property.params["RELTYPE"]), "PARENT") == "PARENT" {
+				todo.parentUid = unescapeICSText(property.value)
+			}
+		case "RRULE", icsRepeatProperty:
+			if recurrence, error := ParseRecurrence(property.value); error == nil {
+				todo.task.Repeat = &recurrence
+			}
+		case "DTSTAMP", "CREATED", "LAST-MODIFIED", "COMPLETED", "DUE":
+
+			moment, error := parseICSTime(property)
+
+			if error != nil {
+				return todo, fmt.Errorf("%s %q %w", property.name, property.value, ErrInvalidICS)
+			}
+
+			switch property.name {
+			case "DTSTAMP":
+				stamped = &moment
+			case "CREATED":
+				created = &moment
+			case "LAST-MODIFIED":
+				lastModified = &moment
+			case "COMPLETED":
+				todo.task.Status = DONE
+				completed = &moment
+			case "DUE":
+				todo.task.Due = &moment
+			}
+		}
+	}
+
+	// DTSTAMP is when the to-do was last written so it stands in for the times that are missing.
+	if createdAt := lo.CoalesceOrEmpty(created, stamped); createdAt != nil {
+		todo.task.createdAt = createdAt.UnixMicro()
+	}
+
+	if updatedAt := lo.CoalesceOrEmpty(lastModified, completed, stamped); updatedAt != nil {
+		todo.task.UpdatedAt = *updatedAt
+	}
+
+	return todo, nil
+}
+
+// ReadICS reads every VTODO in a calendar into a task for list.
+// SUMMARY is the title, PRIORITY 1 to 4 is high, 5 is medium and the rest are low,
+// a COMPLETED status or date completes it and CATEGORIES are its tags.
+// A RELATED-TO that names another to-do in the file makes it a subtask of that to-do.
+func ReadICS(reader io.Reader, list string) ([]Task, error) {
+
+	lines, starts, error := unfoldICS(reader)
+
+	if error != nil {
+		return nil, error
+	}
+
+	todos := []icsTodo{}
+
+	var properties []icsProperty
+
+	inTodo, nested := false, 0
+
+	for index, line := range lines {
+
+		property, ok := parseICSProperty(line)
+
+		if !ok {
+			return nil, fmt.Errorf("line %d %q %w", starts[index], line, ErrInvalidICS)
+		}
+
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VTODO"):
+			inTodo, nested, properties = true, 0, []icsProperty{}
+
+		case property.name == "END" && strings.EqualFold(property.value, "VTODO"):
+
+			if !inTodo {
+				return nil, fmt.Errorf("line %d END:VTODO without a BEGIN:VTODO %w", starts[index], ErrInvalidICS)
+			}
+
+			todo, error := readICSTodo(properties, list)
+
+			if error != nil {
+				return nil, fmt.Errorf("line %d %w", starts[index], error)
+			}
+
+			todos = append(todos, todo)
+			inTodo = false
+
+		// Components inside a to-do like VALARM have properties of their own that aren't the to-do's.
+		case inTodo && property.name == "BEGIN":
+			nested++
+
+		case inTodo && property.name == "END" && nested > 0:
+			nested--
+
+		case inTodo && nested == 0:
+			properties = append(properties, property)
+		}
+	}
+
+	if inTodo {
+		return nil, fmt.Errorf("a VTODO isn't closed with END:VTODO %w", ErrInvalidICS)
+	}
+
+	return lo.Map(todos, func(todo icsTodo, index int) Task {
+
+		if parent, ok := lo.Find(todos, func(other icsTodo) bool {
+			return todo.parentUid != "" && other.task.icsUid() == todo.parentUid
+		}); ok {
+			todo.task.ParentId = parent.task.id
+		}
+
+		return todo.task
+	}), nil
+}
@@ -0,0 +1,239 @@
+package task
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var ErrAmbiguousId = errors.New("is the start of more than one task id")
+
+// AmbiguousIdError lists every task whose id starts with the prefix.
+type AmbiguousIdError struct {
+	Prefix     string
+	Candidates []Task
+}
+
+func (self AmbiguousIdError) Error() string {
+
+	candidates := lo.Map(self.Candidates, func(item Task, index int) string {
+		return fmt.Sprintf("  %s %s", item.id, item.Title)
+	})
+
+	return fmt.Sprintf(
+		"%q %s\n%s",
+		self.Prefix,
+		ErrAmbiguousId,
+		strings.Join(candidates, "\n"),
+	)
+}
+
+func (self AmbiguousIdError) Unwrap() error {
+
+	return ErrAmbiguousId
+}
+
+// MIN_SHORT_ID_LENGTH keeps short ids long enough to read as ids.
+const MIN_SHORT_ID_LENGTH = 4
+
+// Number is the task's position in its list when it was added.
+// It never changes when other tasks are deleted so it can be used instead of the id.
+func (self Task) Number() int {
+
+	return self.number
+}
+
+// nextNumber is one after the highest number the list has given a task
+// so the number of a deleted task is never given to another one.
+func nextNumber(tasks []Task, list string, highest map[string]int) int {
+
+	return max(lo.Max(lo.Map(InList(tasks, list), func(item Task, index int) int {
+		return item.number
+	})), highest[list]) + 1
+}
+
+// numberTasks gives tasks written before there were numbers one after the highest number in their list.
+// The oldest task gets the lowest number so the result is the same every time it's worked out.
+// It returns whether any task was numbered so stores know to write the numbers back.
+func numberTasks(tasks []Task, highest map[string]int) ([]Task, bool) {
+
+	unnumbered := lo.Filter(tasks, func(item Task, index int) bool {
+		return item.number == 0
+	})
+
+	if len(unnumbered) == 0 {
+		return tasks, false
+	}
+
+	slices.SortStableFunc(unnumbered, func(a, b Task) int {
+		return cmp.Compare(a.createdAt, b.createdAt)
+	})
+
+	numbered := slices.Clone(tasks)
+
+	for _, item := range unnumbered {
+
+		index := slices.IndexFunc(numbered, func(other Task) bool {
+			return other.id == item.id
+		})
+
+		numbered[index].number = nextNumber(numbered, item.List, highest)
+	}
+
+	return numbered, true
+}
+
+// putNumberedTask numbers a task that doesn't have a number yet before putting it.
+func putNumberedTask(tasks []Task, task Task, highest map[string]int) []Task {
+
+	if task.number == 0 {
+		task.number = nextNumber(tasks, task.List, highest)
+	}
+
+	return putTask(tasks, task)
+}
+
+// highestNumbers is the highest number of the tasks in each list.
+func highestNumbers(tasks []Task) map[string]int {
+
+	highest := map[string]int{}
+
+	for _, item := range tasks {
+		highest[item.List] = max(highest[item.List], item.number)
+	}
+
+	return highest
+}
+
+// raiseNumbers takes the higher number of each list from highest and numbers.
+// It returns whether any of them went up.
+func raiseNumbers(highest, numbers map[string]int) (map[string]int, bool) {
+
+	raised := false
+
+	for list, number := range numbers {
+
+		if number > highest[list] {
+			highest = lo.Assign(highest, map[string]int{list: number})
+			raised = true
+		}
+	}
+
+	return highest, raised
+}
+
+// readHighestNumbers reads the highest numbers from the list registry next to the task file.
+func readHighestNumbers(storePath string) (map[string]int, error) {
+
+	registry, error := ReadListRegistry(ListRegistryPath(storePath))
+
+	return registry.Numbers, error
+}
+
+// recordHighestNumbers writes the numbers tasks have to the list registry when they're higher than the recorded ones.
+func recordHighestNumbers(storePath string, tasks []Task) error {
+
+	registry, error := ReadListRegistry(ListRegistryPath(storePath))
+
+	if error != nil {
+		return error
+	}
+
+	numbers, raised := raiseNumbers(registry.Numbers, highestNumbers(tasks))
+
+	if !raised {
+		return nil
+	}
+
+	registry.Numbers = numbers
+
+	return registry.Save()
+}
+
+// ClearNumber lets the store give a task a new number like when it moves to another list.
+func (self Task) ClearNumber() Task {
+
+	self.number = 0
+
+	return self
+}
+
+var numberPattern = regexp.MustCompile(`^#?(\d+)$`)
+
+// Resolve finds the task a reference points at.
+// A reference is a full id, a number in the list like 12 or #12, or the start of an id.
+// A number wins over an id that happens to start with the same digits.
+// It returns an AmbiguousIdError when a prefix matches more than one task.
+func Resolve(tasks []Task, reference, list string) (Task, error) {
+
+	if found, ok := lo.Find(tasks, func(item Task) bool { return item.id == reference }); ok {
+		return found, nil
+	}
+
+	if matches := numberPattern.FindStringSubmatch(reference); matches != nil {
+
+		number, _ := strconv.Atoi(matches[1])
+
+		if found, ok := lo.Find(InList(tasks, list), func(item Task) bool { return item.number == number }); ok {
+			return found, nil
+		}
+	}
+
+	candidates := lo.Filter(tasks, func(item Task, index int) bool {
+		return reference != "" && strings.HasPrefix(item.id, reference)
+	})
+
+	switch len(candidates) {
+	case 0:
+		return Task{}, fmt.Errorf("%w %s", ErrTaskNotFound, reference)
+	case 1:
+		return candidates[0], nil
+	}
+
+	return Task{}, AmbiguousIdError{Prefix: reference, Candidates: candidates}
+}
+
+// ShortIds maps every id to the shortest prefix no other id starts with.
+func ShortIds(tasks []Task) map[string]string {
+
+	ids := lo.Map(tasks, func(item Task, index int) string { return item.id })
+
+	slices.Sort(ids)
+
+	shortIds := make(map[string]string, len(ids))
+
+	for index, id := range ids {
+
+		length := MIN_SHORT_ID_LENGTH
+
+		for _, neighbour := range []int{index - 1, index + 1} {
+
+			if neighbour < 0 || neighbour >= len(ids) {
+				continue
+			}
+
+			length = max(length, commonPrefixLength(id, ids[neighbour])+1)
+		}
+
+		shortIds[id] = id[:min(length, len(id))]
+	}
+
+	return shortIds
+}
+
+func commonPrefixLength(a, b string) int {
+
+	length := 0
+
+	for length < len(a) && length < len(b) && a[length] == b[length] {
+		length++
+	}
+
+	return length
+}
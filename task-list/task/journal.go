@@ -0,0 +1,623 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+var ErrNothingToUndo = errors.New("There's nothing to undo")
+
+var ErrNothingToRedo = errors.New("There's nothing to redo")
+
+var ErrChangedSince = errors.New("has changed since")
+
+// ChangedSinceError is returned when undoing or redoing would throw away a later change to a task.
+type ChangedSinceError struct {
+	Id        string
+	Operation Operation
+}
+
+func (self ChangedSinceError) Error() string {
+
+	return fmt.Sprintf("The task %s %s %s", self.Id, ErrChangedSince, self.Operation.Command)
+}
+
+func (self ChangedSinceError) Unwrap() error {
+
+	return ErrChangedSince
+}
+
+const (
+	changeRecord = "change"
+	undoRecord   = "undo"
+	redoRecord   = "redo"
+)
+
+// journalRecord is one line of the journal.
+// A change holds a task before and after an operation changed it and is empty on the side where it didn't exist.
+// Undo and redo records name the operation they undid or redid.
+type journalRecord struct {
+	Kind          string          `json:"kind"`
+	Operation     string          `json:"operation"`
+	Time          int64           `json:"time"`
+	Command       string          `json:"command,omitempty"`
+	SchemaVersion int             `json:"schemaVersion,omitempty"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+}
+
+// Change is a task before and after an operation.
+// Before is nil when the task was added and After is nil when it was deleted.
+type Change struct {
+	Before *Task
+	After  *Task
+}
+
+func (self Change) Id() string {
+
+	return lo.Ternary(self.After != nil, self.After, self.Before).id
+}
+
+// Operation is every change one command made.
+type Operation struct {
+	Id      string
+	Time    time.Time
+	Command string
+	Changes []Change
+	Undone  bool
+}
+
+// JournalPath swaps the extension of the task file so
+// task-list.json keeps its journal in task-list.journal.jsonl.
+// Stores without a file keep their journal in memory.
+func JournalPath(storePath string) string {
+
+	if storePath == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(storePath, filepath.Ext(storePath)) + ".journal.jsonl"
+}
+
+// Journal records every change so it can be undone and redone.
+// It's only ever appended to.
+type Journal struct {
+	path    string
+	records *[]journalRecord
+}
+
+func OpenJournal(path string) Journal {
+
+	return Journal{path: path, records: &[]journalRecord{}}
+}
+
+func (self Journal) append(records ...journalRecord) error {
+
+	if self.path == "" {
+		*self.records = append(*self.records, records...)
+		return nil
+	}
+
+	var buffer bytes.Buffer
+
+	for _, record := range records {
+
+		byte, error := json.Marshal(record)
+
+		if error != nil {
+			return error
+		}
+
+		buffer.Write(byte)
+		buffer.WriteByte('\n')
+	}
+
+	file, error := os.OpenFile(self.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+
+	if error != nil {
+		return error
+	}
+
+	if _, error := file.Write(buffer.Bytes()); error != nil {
+		file.Close()
+		return error
+	}
+
+	return file.Close()
+}
+
+func (self Journal) read() ([]journalRecord, error) {
+
+	if self.path == "" {
+		return *self.records, nil
+	}
+
+	contents, error := os.ReadFile(self.path)
+
+	if errors.Is(error, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if error != nil {
+		return nil, error
+	}
+
+	records := []journalRecord{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var record journalRecord
+
+		if error := json.Unmarshal(scanner.Bytes(), &record); error != nil {
+			return nil, fmt.Errorf("%s:%d %w", self.path, line, error)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func decodeSnapshot(version int, raw json.RawMessage) (*Task, error) {
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	persisted, error := migrateTask(version, raw)
+
+	if error != nil {
+		return nil, error
+	}
+
+	return lo.ToPtr(persisted.toTask()), nil
+}
+
+func encodeSnapshot(task *Task) (json.RawMessage, error) {
+
+	if task == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(task.toPersistedTask())
+}
+
+// history replays the journal into operations oldest first
+// along with the ids that can be undone and redone, most recent last.
+// Starting a new operation after an undo means the undone ones can't be redone anymore.
+func (self Journal) history() ([]Operation, []string, []string, error) {
+
+	records, error := self.read()
+
+	if error != nil {
+		return nil, nil, nil, error
+	}
+
+	operations := []Operation{}
+	indexes := map[string]int{}
+	done, undone := []string{}, []string{}
+
+	for _, record := range records {
+
+		switch record.Kind {
+		case changeRecord:
+
+			before, beforeError := decodeSnapshot(record.SchemaVersion, record.Before)
+			after, afterError := decodeSnapshot(record.SchemaVersion, record.After)
+
+			if error := errors.Join(beforeError, afterError); error != nil {
+				return nil, nil, nil, error
+			}
+
+			index, ok := indexes[record.Operation]
+
+			if !ok {
+				index = len(operations)
+				indexes[record.Operation] = index
+				operations = append(operations, Operation{
+					Id:      record.Operation,
+					Time:    time.UnixMicro(record.Time),
+					Command: record.Command,
+				})
+				done = append(done, record.Operation)
+				undone = nil
+			}
+
+			operations[index].Changes = append(operations[index].Changes, Change{Before: before, After: after})
+
+		case undoRecord:
+			done = lo.Without(done, record.Operation)
+			undone = append(undone, record.Operation)
+
+		case redoRecord:
+			undone = lo.Without(undone, record.Operation)
+			done = append(done, record.Operation)
+		}
+	}
+
+	for index := range operations {
+		operations[index].Undone = !lo.Contains(done, operations[index].Id)
+	}
+
+	return operations, done, undone, nil
+}
+
+// Operations is every operation in the journal oldest first.
+func (self Journal) Operations() ([]Operation, error) {
+
+	operations, _, _, error := self.history()
+
+	return operations, error
+}
+
+func findOperation(operations []Operation, id string) Operation {
+
+	operation, _ := lo.Find(operations, func(item Operation) bool { return item.Id == id })
+
+	return operation
+}
+
+// Undo reverts the last count operations that haven't been undone, most recent first.
+// A task that changed after the operation is only reverted when force is true.
+func (self Journal) Undo(store TaskStore, count int, force bool) ([]Operation, error) {
+
+	operations, done, _, error := self.history()
+
+	if error != nil {
+		return nil, error
+	}
+
+	if len(done) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return nil, error
+	}
+
+	undone := []Operation{}
+
+	for _, id := range slices.Backward(done[max(len(done)-count, 0):]) {
+
+		operation := findOperation(operations, id)
+
+		changes := lo.Map(operation.Changes, func(change Change, index int) Change {
+			return Change{Before: change.After, After: change.Before}
+		})
+
+		var puts []Task
+		var deletedIds []string
+
+		tasks, puts, deletedIds, error = self.apply(tasks, operation, slices.Backward(changes), force)
+
+		if error != nil {
+			return undone, error
+		}
+
+		// Each operation is saved before it's recorded as undone so a failed save leaves the journal as it was.
+		if error := SaveChanges(store, puts, deletedIds); error != nil {
+			return undone, error
+		}
+
+		if error := self.append(journalRecord{Kind: undoRecord, Operation: id, Time: time.Now().UnixMicro()}); error != nil {
+			return undone, error
+		}
+
+		undone = append(undone, operation)
+	}
+
+	return undone, nil
+}
+
+// Redo makes the changes of the operation that was undone last again.
+func (self Journal) Redo(store TaskStore, force bool) (Operation, error) {
+
+	operations, _, undone, error := self.history()
+
+	if error != nil {
+		return Operation{}, error
+	}
+
+	if len(undone) == 0 {
+		return Operation{}, ErrNothingToRedo
+	}
+
+	operation := findOperation(operations, undone[len(undone)-1])
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return operation, error
+	}
+
+	_, puts, deletedIds, error := self.apply(tasks, operation, slices.All(operation.Changes), force)
+
+	if error != nil {
+		return operation, error
+	}
+
+	if error := SaveChanges(store, puts, deletedIds); error != nil {
+		return operation, error
+	}
+
+	return operation, self.append(journalRecord{Kind: redoRecord, Operation: operation.Id, Time: time.Now().UnixMicro()})
+}
+
+// apply moves every task from Before to After.
+// It returns the tasks afterwards along with the tasks to put and the ids to delete to get there.
+// Tasks are checked to still be at Before first so nothing is changed when one isn't.
+func (self Journal) apply(tasks []Task, operation Operation, changes iter.Seq2[int, Change], force bool) ([]Task, []Task, []string, error) {
+
+	if !force {
+
+		checked := map[string]bool{}
+
+		// A task changed twice by one operation is checked against the first change applied to it.
+		for _, change := range changes {
+
+			if checked[change.Id()] {
+				continue
+			}
+
+			checked[change.Id()] = true
+
+			current, error := findTask(tasks, change.Id())
+
+			exists := error == nil
+
+			if exists != (change.Before != nil) || exists && !sameContents(current, *change.Before) {
+				return nil, nil, nil, ChangedSinceError{Id: change.Id(), Operation: operation}
+			}
+		}
+	}
+
+	applied := slices.Clone(tasks)
+	ids := []string{}
+
+	for _, change := range changes {
+
+		ids = append(ids, change.Id())
+
+		current, error := findTask(applied, change.Id())
+
+		exists := error == nil
+
+		if change.After == nil {
+			applied = deleteTasks(applied, []string{change.Id()})
+			continue
+		}
+
+		// The revision moves forward so anyone holding the task they read sees it changed.
+		restored := *change.After
+
+		if exists {
+			restored = restored.Rebase(current)
+		}
+
+		applied = putTask(applied, restored)
+	}
+
+	ids = lo.Uniq(ids)
+
+	puts := lo.FilterMap(ids, func(id string, index int) (Task, bool) {
+		item, error := findTask(applied, id)
+		return item, error == nil
+	})
+
+	deletedIds := lo.Filter(ids, func(id string, index int) bool {
+		_, existedError := findTask(tasks, id)
+		_, existsError := findTask(applied, id)
+		return existedError == nil && existsError != nil
+	})
+
+	return applied, puts, deletedIds, nil
+}
+
+// sameContents ignores the revision and when the task was updated
+// because undoing moves them forward instead of back.
+func sameContents(a, b Task) bool {
+
+	a.revision, a.UpdatedAt = 0, time.Time{}
+	b.revision, b.UpdatedAt = 0, time.Time{}
+
+	aJSON, aError := a.ToJSON()
+	bJSON, bError := b.ToJSON()
+
+	return aError == nil && bError == nil && aJSON == bJSON
+}
+
+// journaledStore records every change made through it as one operation.
+type journaledStore struct {
+	TaskStore
+	journal   Journal
+	operation string
+	command   string
+}
+
+// NewJournaledStore records the changes made through store in the journal.
+// Every change is part of one operation described by command.
+func NewJournaledStore(store TaskStore, journal Journal, command string) TaskStore {
+
+	return journaledStore{
+		TaskStore: store,
+		journal:   journal,
+		operation: uuid.NewString(),
+		command:   command,
+	}
+}
+
+func (self journaledStore) record(before, after *Task) error {
+
+	rawBefore, beforeError := encodeSnapshot(before)
+	rawAfter, afterError := encodeSnapshot(after)
+
+	if error := errors.Join(beforeError, afterError); error != nil {
+		return error
+	}
+
+	return self.journal.append(journalRecord{
+		Kind:          changeRecord,
+		Operation:     self.operation,
+		Time:          time.Now().UnixMicro(),
+		Command:       self.command,
+		SchemaVersion: CurrentSchemaVersion(),
+		Before:        rawBefore,
+		After:         rawAfter,
+	})
+}
+
+// storedTask is nil when there's no task with the id.
+func (self journaledStore) storedTask(id string) (*Task, error) {
+
+	task, error := self.TaskStore.Get(id)
+
+	if errors.Is(error, ErrTaskNotFound) {
+		return nil, nil
+	}
+
+	if error != nil {
+		return nil, error
+	}
+
+	return &task, nil
+}
+
+func (self journaledStore) Put(task Task) error {
+
+	before, error := self.storedTask(task.id)
+
+	if error != nil {
+		return error
+	}
+
+	if error := self.TaskStore.Put(task); error != nil {
+		return error
+	}
+
+	after, error := self.storedTask(task.id)
+
+	if error != nil {
+		return error
+	}
+
+	return self.record(before, after)
+}
+
+func (self journaledStore) Delete(ids ...string) error {
+
+	tasks, error := self.TaskStore.Load()
+
+	if error != nil {
+		return error
+	}
+
+	if error := self.TaskStore.Delete(ids...); error != nil {
+		return error
+	}
+
+	for _, id := range ids {
+
+		if before, error := findTask(tasks, id); error == nil {
+
+			if error := self.record(&before, nil); error != nil {
+				return error
+			}
+		}
+	}
+
+	return nil
+}
+
+// Save records the tasks that were added, changed or deleted.
+func (self journaledStore) Save(tasks []Task) error {
+
+	previousTasks, error := self.TaskStore.Load()
+
+	if error != nil {
+		return error
+	}
+
+	if error := self.TaskStore.Save(tasks); error != nil {
+		return error
+	}
+
+	savedTasks, error := self.TaskStore.Load()
+
+	if error != nil {
+		return error
+	}
+
+	ids := lo.Uniq(lo.Map(append(slices.Clone(previousTasks), savedTasks...), func(item Task, index int) string {
+		return item.id
+	}))
+
+	for _, id := range ids {
+
+		before, beforeError := findTask(previousTasks, id)
+		after, afterError := findTask(savedTasks, id)
+
+		if beforeError == nil && afterError == nil && before.revision == after.revision {
+			continue
+		}
+
+		if error := self.record(
+			lo.Ternary(beforeError == nil, &before, nil),
+			lo.Ternary(afterError == nil, &after, nil),
+		); error != nil {
+			return error
+		}
+	}
+
+	return nil
+}
+
+type persistedOperation struct {
+	Id      string   `json:"id"`
+	Time    int64    `json:"time"`
+	Command string   `json:"command"`
+	Undone  bool     `json:"undone"`
+	Tasks   []string `json:"tasks"`
+}
+
+func MarshallOperations(operations []Operation) (string, error) {
+
+	persistedOperations := lo.Map(operations, func(operation Operation, index int) persistedOperation {
+		return persistedOperation{
+			Id:      operation.Id,
+			Time:    operation.Time.UnixMicro(),
+			Command: operation.Command,
+			Undone:  operation.Undone,
+			Tasks:   lo.Uniq(lo.Map(operation.Changes, func(change Change, index int) string { return change.Id() })),
+		}
+	})
+
+	byte, error := json.Marshal(&persistedOperations)
+
+	if error != nil {
+		return "", error
+	}
+
+	return string(byte), nil
+}
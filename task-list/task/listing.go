@@ -0,0 +1,112 @@
+package task
+
+import (
+	"encoding/json"
+
+	"github.com/samber/lo"
+)
+
+type blocker struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// listedTask is how list shows a task.
+// Blockers names the incomplete tasks it's waiting on.
+type listedTask struct {
+	persistedTask
+	ShortId  string    `json:"shortId"`
+	Blocked  bool      `json:"blocked"`
+	Blockers []blocker `json:"blockers,omitempty"`
+}
+
+type persistedListGroup struct {
+	List  string       `json:"list"`
+	Tasks []listedTask `json:"tasks"`
+}
+
+// Listing shows tasks along with what's worked out from every task in the store
+// like their shortest unique id and their blockers.
+// It's built from every task so that holds even when some of them were filtered out.
+type Listing struct {
+	all      []Task
+	shortIds map[string]string
+}
+
+func NewListing(all []Task) Listing {
+
+	return Listing{all: all, shortIds: ShortIds(all)}
+}
+
+func (self Listing) ShortId(task Task) string {
+
+	return lo.CoalesceOrEmpty(self.shortIds[task.id], task.id)
+}
+
+// Blocked is true when the task waits on an incomplete task anywhere in the store.
+func (self Listing) Blocked(task Task) bool {
+
+	return task.Blocked(self.all)
+}
+
+// Blockers are the incomplete tasks anywhere in the store the task waits on.
+func (self Listing) Blockers(task Task) []Task {
+
+	return task.Blockers(self.all)
+}
+
+func (self Listing) listedTask(task Task) listedTask {
+
+	blockers := lo.Map(task.Blockers(self.all), func(item Task, index int) blocker {
+		return blocker{Id: item.id, Title: item.Title}
+	})
+
+	return listedTask{
+		persistedTask: task.toPersistedTask(),
+		ShortId:       self.ShortId(task),
+		Blocked:       len(blockers) > 0,
+		Blockers:      blockers,
+	}
+}
+
+func (self Listing) ToJSON(task Task) (string, error) {
+
+	byte, error := json.Marshal(self.listedTask(task))
+
+	return string(byte), error
+}
+
+func (self Listing) Marshall(tasks []Task) (string, error) {
+
+	listedTasks := lo.Map(tasks, func(item Task, index int) listedTask {
+		return self.listedTask(item)
+	})
+
+	byte, error := json.Marshal(&listedTasks)
+
+	if error != nil {
+		return "", error
+	}
+
+	return string(byte), nil
+}
+
+func (self Listing) MarshallGroups(groups []ListGroup) (string, error) {
+
+	persistedGroups := lo.Map(groups, func(group ListGroup, index int) persistedListGroup {
+		return persistedListGroup{
+			List: group.List,
+			Tasks: lo.Map(group.Tasks, func(item Task, index int) listedTask {
+				return self.listedTask(item)
+			}),
+		}
+	})
+
+	byte, error := json.Marshal(&persistedGroups)
+
+	if error != nil {
+		return "", error
+	}
+
+	return string(byte), nil
+}
@@ -0,0 +1,250 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// DEFAULT_LIST holds tasks that were added before there were lists
+// and is the active list until another one is used.
+const DEFAULT_LIST = "default"
+
+var ErrListNotFound = errors.New("There's no list called")
+
+var ErrListExists = errors.New("There's already a list called")
+
+var listNamePattern = regexp.MustCompile(`^[^\s,]+$`)
+
+// ParseListName trims a list name and makes sure it's a single word without commas.
+func ParseListName(input string) (string, error) {
+
+	name := strings.TrimSpace(input)
+
+	if !listNamePattern.MatchString(name) {
+		return "", fmt.Errorf("Wrong list name %q a list name can't be empty or contain spaces or commas", input)
+	}
+
+	return name, nil
+}
+
+// ListRegistry remembers which lists exist and which one is active.
+// It lives next to the task file so every store has its own lists.
+// The default list always exists so it's never stored.
+// Numbers is the highest number each list has given a task so deleting a task never frees its number.
+type ListRegistry struct {
+	Active  string         `json:"active,omitempty"`
+	Lists   []string       `json:"lists"`
+	Numbers map[string]int `json:"numbers,omitempty"`
+	path    string
+}
+
+// ListRegistryPath swaps the extension of the task file so
+// task-list.json keeps its lists in task-list.lists.json.
+// Stores without a file keep their lists in memory.
+func ListRegistryPath(storePath string) string {
+
+	if storePath == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(storePath, filepath.Ext(storePath)) + ".lists.json"
+}
+
+// ReadListRegistry treats a missing file as a registry with only the default list.
+func ReadListRegistry(path string) (ListRegistry, error) {
+
+	registry := ListRegistry{path: path}
+
+	if path == "" {
+		return registry, nil
+	}
+
+	contents, error := os.ReadFile(path)
+
+	if errors.Is(error, os.ErrNotExist) {
+		return registry, nil
+	}
+
+	if error != nil {
+		return registry, error
+	}
+
+	if error := json.Unmarshal(contents, &registry); error != nil {
+		return registry, fmt.Errorf("%s %w", path, error)
+	}
+
+	return registry, nil
+}
+
+// Save keeps the higher of each number on disk and in the registry
+// because the task store raises them while a command that read the registry runs.
+func (self ListRegistry) Save() error {
+
+	if self.path == "" {
+		return nil
+	}
+
+	stored, error := ReadListRegistry(self.path)
+
+	if error != nil {
+		return error
+	}
+
+	self.Numbers, _ = raiseNumbers(self.Numbers, stored.Numbers)
+
+	contents, error := json.MarshalIndent(self, "", "  ")
+
+	if error != nil {
+		return error
+	}
+
+	return writeFileAtomically(self.path, contents)
+}
+
+// Names starts with the default list and keeps the rest in the order they were created.
+func (self ListRegistry) Names() []string {
+
+	return lo.Uniq(append([]string{DEFAULT_LIST}, self.Lists...))
+}
+
+func (self ListRegistry) Has(name string) bool {
+
+	return lo.Contains(self.Names(), name)
+}
+
+// ActiveList falls back to the default list when the active one was never set.
+func (self ListRegistry) ActiveList() string {
+
+	return lo.CoalesceOrEmpty(self.Active, DEFAULT_LIST)
+}
+
+// Resolve picks the list a command works on.
+// A name passed in wins over the active list and has to exist.
+func (self ListRegistry) Resolve(name string) (string, error) {
+
+	if name == "" {
+		return self.ActiveList(), nil
+	}
+
+	if !self.Has(name) {
+		return "", fmt.Errorf("%w %s", ErrListNotFound, name)
+	}
+
+	return name, nil
+}
+
+func (self *ListRegistry) Create(input string) (string, error) {
+
+	name, error := ParseListName(input)
+
+	if error != nil {
+		return "", error
+	}
+
+	if self.Has(name) {
+		return "", fmt.Errorf("%w %s", ErrListExists, name)
+	}
+
+	self.Lists = append(self.Lists, name)
+
+	return name, nil
+}
+
+// Rename keeps the active list pointing at the renamed list.
+// The default list can't be renamed because tasks without a list belong to it.
+func (self *ListRegistry) Rename(from, input string) (string, error) {
+
+	if from == DEFAULT_LIST {
+		return "", fmt.Errorf("The %s list can't be renamed", DEFAULT_LIST)
+	}
+
+	if !self.Has(from) {
+		return "", fmt.Errorf("%w %s", ErrListNotFound, from)
+	}
+
+	to, error := ParseListName(input)
+
+	if error != nil {
+		return "", error
+	}
+
+	if self.Has(to) {
+		return "", fmt.Errorf("%w %s", ErrListExists, to)
+	}
+
+	self.Lists[slices.Index(self.Lists, from)] = to
+
+	if number, ok := self.Numbers[from]; ok {
+		self.Numbers[to] = number
+	}
+
+	if self.Active == from {
+		self.Active = to
+	}
+
+	return to, nil
+}
+
+// Delete makes the default list active again when the active list is deleted.
+func (self *ListRegistry) Delete(name string) error {
+
+	if name == DEFAULT_LIST {
+		return fmt.Errorf("The %s list can't be deleted", DEFAULT_LIST)
+	}
+
+	if !self.Has(name) {
+		return fmt.Errorf("%w %s", ErrListNotFound, name)
+	}
+
+	self.Lists = lo.Without(self.Lists, name)
+
+	if self.Active == name {
+		self.Active = ""
+	}
+
+	return nil
+}
+
+func (self *ListRegistry) Use(name string) error {
+
+	if !self.Has(name) {
+		return fmt.Errorf("%w %s", ErrListNotFound, name)
+	}
+
+	self.Active = lo.Ternary(name == DEFAULT_LIST, "", name)
+
+	return nil
+}
+
+func InList(tasks []Task, list string) []Task {
+
+	return lo.Filter(tasks, func(item Task, index int) bool {
+		return item.List == list
+	})
+}
+
+// ListGroup is a list along with the tasks in it.
+type ListGroup struct {
+	List  string
+	Tasks []Task
+}
+
+// GroupByList keeps the order of names and adds lists that only tasks mention at the end.
+func GroupByList(tasks []Task, names []string) []ListGroup {
+
+	names = lo.Uniq(append(slices.Clone(names), lo.Map(tasks, func(item Task, index int) string {
+		return item.List
+	})...))
+
+	return lo.Map(names, func(name string, index int) ListGroup {
+		return ListGroup{List: name, Tasks: InList(tasks, name)}
+	})
+}
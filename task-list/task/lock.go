@@ -0,0 +1,58 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var ErrLocked = errors.New("The task file is locked by another task-list process")
+
+var errWouldBlock = errors.New("lock is held")
+
+const lockPollInterval = 50 * time.Millisecond
+
+// lockFile takes an advisory lock on a file that sits next to path.
+// It waits up to timeout for another process to release the lock.
+// The returned function releases it.
+func lockFile(path string, timeout time.Duration) (func() error, error) {
+
+	lockPath := path + ".lock"
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+
+		err := tryLock(file)
+
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, errWouldBlock) {
+			file.Close()
+			return nil, err
+		}
+
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w %s (waited %s)", ErrLocked, path, timeout)
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() error {
+
+		return errors.Join(unlock(file), file.Close())
+
+	}, nil
+
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package task
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func tryLock(file *os.File) error {
+
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errWouldBlock
+	}
+
+	return err
+}
+
+func unlock(file *os.File) error {
+
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
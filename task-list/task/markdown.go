@@ -0,0 +1,161 @@
+package task
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const MARKDOWN = "markdown"
+
+var (
+	markdownItemPattern    = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*)$`)
+	markdownHeadingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+)
+
+// WriteMarkdown writes a checklist of the tasks under a heading for each priority, highest first.
+// A description goes under its task indented so it stays part of the item
+// and subtasks with the same priority as their parent are indented under it.
+func WriteMarkdown(writer io.Writer, tasks []Task) error {
+
+	sections := []string{}
+
+	for _, level := range []priority{HIGH, MEDIUM, LOW} {
+
+		items := lo.Filter(tasks, func(item Task, index int) bool { return item.Priority == level })
+
+		if len(items) == 0 {
+			continue
+		}
+
+		lines := []string{"## " + lo.Capitalize(level.Value()), ""}
+
+		var writeItems func(parentId, indent string)
+
+		writeItems = func(parentId, indent string) {
+
+			for _, item := range items {
+
+				isChild := item.ParentId != "" && lo.ContainsBy(items, func(other Task) bool { return other.id == item.ParentId })
+
+				if lo.Ternary(parentId == "", isChild, item.ParentId != parentId) {
+					continue
+				}
+
+				lines = append(lines, fmt.Sprintf("%s- [%s] %s", indent, lo.Ternary(item.Done(), "x", " "), item.Title))
+
+				if item.Description != "" {
+					for _, line := range strings.Split(item.Description, "\n") {
+						lines = append(lines, strings.TrimRight(indent+"  "+line, " "))
+					}
+				}
+
+				writeItems(item.id, indent+"  ")
+			}
+		}
+
+		writeItems("", "")
+
+		sections = append(sections, strings.Join(lines, "\n")+"\n")
+	}
+
+	_, error := io.WriteString(writer, strings.Join(sections, "\n"))
+
+	return error
+}
+
+// markdownIndent counts a tab as four spaces like Markdown does.
+func markdownIndent(line string) int {
+
+	whitespace := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+	return len(strings.ReplaceAll(whitespace, "\t", "    "))
+}
+
+type markdownItem struct {
+	task        Task
+	indent      int
+	description []string
+}
+
+// ReadMarkdown reads every checklist item in a Markdown file into a task for list.
+// Headings named after a priority like ## High set the priority of the items under them
+// and other headings go back to low.
+// Lines indented under an item are its description and items indented under another are its subtasks.
+// Everything else like paragraphs and plain list items is skipped.
+func ReadMarkdown(reader io.Reader, list string) ([]Task, error) {
+
+	items := []*markdownItem{}
+	open := []*markdownItem{}
+	blankLines := 0
+	level := LOW
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+
+		line := scanner.Text()
+
+		if strings.TrimSpace(line) == "" {
+			blankLines++
+			continue
+		}
+
+		indent := markdownIndent(line)
+
+		// Close the items this line isn't indented under.
+		open = lo.Filter(open, func(item *markdownItem, index int) bool { return indent > item.indent })
+
+		if matches := markdownItemPattern.FindStringSubmatch(line); matches != nil {
+
+			item := &markdownItem{task: NewTask(strings.TrimSpace(matches[3]), ""), indent: indent}
+			item.task.List = list
+			item.task.Priority = level
+			item.task = item.task.MarkComplete(matches[2] != " ")
+
+			if len(open) > 0 {
+				item.task.ParentId = open[len(open)-1].task.id
+			}
+
+			items = append(items, item)
+			open = append(open, item)
+			blankLines = 0
+
+			continue
+		}
+
+		if len(open) > 0 && indent > 0 {
+
+			parent := open[len(open)-1]
+
+			// Blank lines between the lines of a description are kept as paragraph breaks.
+			if len(parent.description) > 0 {
+				parent.description = append(parent.description, make([]string, blankLines)...)
+			}
+
+			parent.description = append(parent.description, strings.TrimSpace(line))
+			blankLines = 0
+
+			continue
+		}
+
+		open = nil
+		blankLines = 0
+
+		if matches := markdownHeadingPattern.FindStringSubmatch(line); matches != nil {
+
+			heading := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(matches[1]), " priority"))
+
+			level = lo.Ternary(lo.Contains(AllowedProrities, heading), priority(heading), LOW)
+		}
+	}
+
+	return lo.Map(items, func(item *markdownItem, index int) Task {
+		item.task.Description = strings.Join(item.description, "\n")
+		return item.task
+	}), scanner.Err()
+}
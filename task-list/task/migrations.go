@@ -0,0 +1,237 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/samber/lo"
+)
+
+// Migration upgrades tasks stored with the previous schema version to Version.
+// Up receives every task as a generic JSON object so it can rename, fill in or drop keys.
+type Migration struct {
+	Version     int                             `json:"version"`
+	Description string                          `json:"description"`
+	Up          func(task map[string]any) error `json:"-"`
+}
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Wrap the bare task array in an envelope with a schema version and give every task a revision",
+		Up: func(task map[string]any) error {
+
+			if _, ok := task["revision"]; !ok {
+				task["revision"] = 0
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     2,
+		Description: "Replace the complete flag with a status that's done for completed tasks and todo for the rest",
+		Up: func(task map[string]any) error {
+
+			if _, ok := task["status"]; ok {
+				return nil
+			}
+
+			complete, _ := task["complete"].(bool)
+
+			task["status"] = lo.Ternary(complete, DONE, TODO).Value()
+
+			return nil
+		},
+	},
+}
+
+// RegisterMigration adds a migration to the registry.
+// The newest registered version is the schema version new files are written with.
+func RegisterMigration(migration Migration) error {
+
+	if lo.ContainsBy(migrations, func(item Migration) bool {
+		return item.Version == migration.Version
+	}) {
+		return fmt.Errorf("A migration to schema version %d is already registered", migration.Version)
+	}
+
+	migrations = append(migrations, migration)
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+
+	return nil
+}
+
+func CurrentSchemaVersion() int {
+
+	return lo.MaxBy(migrations, func(a, b Migration) bool {
+		return a.Version > b.Version
+	}).Version
+}
+
+// MigrationPlan describes how a task file gets from one schema version to the current one.
+type MigrationPlan struct {
+	From       int         `json:"from"`
+	To         int         `json:"to"`
+	Migrations []Migration `json:"migrations"`
+	Tasks      int         `json:"tasks"`
+}
+
+func (self MigrationPlan) UpToDate() bool {
+
+	return len(self.Migrations) == 0
+}
+
+func planMigration(from int) MigrationPlan {
+
+	return MigrationPlan{
+		From: from,
+		To:   CurrentSchemaVersion(),
+		Migrations: lo.Filter(migrations, func(item Migration, index int) bool {
+			return item.Version > from
+		}),
+	}
+}
+
+// Migrator is implemented by stores that keep tasks in a versioned file.
+type Migrator interface {
+	PlanMigration() (MigrationPlan, error)
+	Migrate() (MigrationPlan, error)
+}
+
+type envelope struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Tasks         []json.RawMessage `json:"tasks"`
+}
+
+// decodeDocument reads either the envelope or the bare array
+// that was written before the schema was versioned.
+func decodeDocument(contents []byte) (int, []json.RawMessage, error) {
+
+	trimmedContents := bytes.TrimSpace(contents)
+
+	if len(trimmedContents) == 0 {
+		return CurrentSchemaVersion(), nil, nil
+	}
+
+	if trimmedContents[0] == '[' {
+
+		var rawTasks []json.RawMessage
+
+		error := json.Unmarshal(trimmedContents, &rawTasks)
+
+		return 0, rawTasks, error
+	}
+
+	var document envelope
+
+	if error := json.Unmarshal(trimmedContents, &document); error != nil {
+		return 0, nil, error
+	}
+
+	return document.SchemaVersion, document.Tasks, nil
+
+}
+
+func encodeDocument(tasks []Task) ([]byte, error) {
+
+	return json.Marshal(struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		Tasks         []persistedTask `json:"tasks"`
+	}{
+		SchemaVersion: CurrentSchemaVersion(),
+		Tasks:         toPersistedTasks(tasks),
+	})
+}
+
+// migrateTask runs every migration newer than version on one stored task.
+func migrateTask(version int, rawTask json.RawMessage) (persistedTask, error) {
+
+	var persisted persistedTask
+
+	if version > CurrentSchemaVersion() {
+		return persisted, fmt.Errorf(
+			"The task file uses schema version %d but this task-list only understands up to %d",
+			version,
+			CurrentSchemaVersion(),
+		)
+	}
+
+	plan := planMigration(version)
+
+	if plan.UpToDate() {
+		error := json.Unmarshal(rawTask, &persisted)
+		return persisted, error
+	}
+
+	var task map[string]any
+
+	if error := json.Unmarshal(rawTask, &task); error != nil {
+		return persisted, error
+	}
+
+	for _, migration := range plan.Migrations {
+
+		if error := migration.Up(task); error != nil {
+			return persisted, fmt.Errorf("migration to schema version %d failed %w", migration.Version, error)
+		}
+	}
+
+	migratedTask, error := json.Marshal(task)
+
+	if error != nil {
+		return persisted, error
+	}
+
+	error = json.Unmarshal(migratedTask, &persisted)
+
+	return persisted, error
+
+}
+
+func migrateTasks(version int, rawTasks []json.RawMessage) ([]persistedTask, error) {
+
+	persistedTasks := make([]persistedTask, 0, len(rawTasks))
+
+	for _, rawTask := range rawTasks {
+
+		persisted, error := migrateTask(version, rawTask)
+
+		if error != nil {
+			return nil, error
+		}
+
+		persistedTasks = append(persistedTasks, persisted)
+	}
+
+	return persistedTasks, nil
+
+}
+
+// migrateStore rewrites a store with the current schema.
+// Loading already upgrades every task so saving is all that's left.
+func migrateStore(store interface {
+	TaskStore
+	Migrator
+}) (MigrationPlan, error) {
+
+	plan, error := store.PlanMigration()
+
+	if error != nil || plan.UpToDate() {
+		return plan, error
+	}
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return plan, error
+	}
+
+	return plan, store.Save(tasks)
+
+}
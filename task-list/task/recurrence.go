@@ -0,0 +1,395 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mini-clis/task-list/dates"
+	"github.com/samber/lo"
+)
+
+var ErrInvalidRecurrence = errors.New("isn't a repeat rule task-list understands")
+
+type Frequency string
+
+const DAILY = Frequency("DAILY")
+const WEEKLY = Frequency("WEEKLY")
+const MONTHLY = Frequency("MONTHLY")
+
+// Recurrence is the subset of RRULE task-list supports.
+// Weekdays only apply to weekly rules and MonthDay only to monthly ones.
+// AfterCompletion counts the interval from when the task was completed instead of when it was due.
+type Recurrence struct {
+	Frequency       Frequency
+	Interval        int
+	Weekdays        []time.Weekday
+	MonthDay        int
+	AfterCompletion bool
+}
+
+var byDayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// String writes the rule the way it's stored like FREQ=WEEKLY;BYDAY=MO,WE.
+func (self Recurrence) String() string {
+
+	parts := []string{fmt.Sprintf("FREQ=%s", self.Frequency)}
+
+	if self.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", self.Interval))
+	}
+
+	if len(self.Weekdays) > 0 {
+		parts = append(parts, fmt.Sprintf(
+			"BYDAY=%s",
+			strings.Join(lo.Map(self.Weekdays, func(item time.Weekday, index int) string {
+				return byDayNames[item]
+			}), ","),
+		))
+	}
+
+	if self.MonthDay > 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", self.MonthDay))
+	}
+
+	if self.AfterCompletion {
+		parts = append(parts, "FROM=COMPLETION")
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Describe reads the rule back in words like every 2 weeks on Mon, Wed.
+func (self Recurrence) Describe() string {
+
+	unit := map[Frequency]string{DAILY: "day", WEEKLY: "week", MONTHLY: "month"}[self.Frequency]
+
+	description := lo.Ternary(
+		self.Interval > 1,
+		fmt.Sprintf("every %d %ss", self.Interval, unit),
+		fmt.Sprintf("every %s", unit),
+	)
+
+	if len(self.Weekdays) > 0 {
+		description += " on " + strings.Join(lo.Map(self.Weekdays, func(item time.Weekday, index int) string {
+			return item.String()[:3]
+		}), ", ")
+	}
+
+	if self.MonthDay > 0 {
+		description += fmt.Sprintf(" on day %d", self.MonthDay)
+	}
+
+	if self.AfterCompletion {
+		description += " after completion"
+	}
+
+	return description
+}
+
+var (
+	everyPattern    = regexp.MustCompile(`^every\s+(?:(\d+)\s+)?(day|week|month)s?(?:\s+on\s+(.+?))?(\s+after\s+completion)?$`)
+	simplePattern   = regexp.MustCompile(`^(daily|weekly|monthly)(?:\s+on\s+(.+?))?(\s+after\s+completion)?$`)
+	monthDayPattern = regexp.MustCompile(`^(?:the\s+)?(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+// ParseRecurrence accepts RRULE parts like FREQ=WEEKLY;BYDAY=MO,WE
+// and phrases like daily, weekly on mon/wed, monthly on the 1st and every 3 days after completion.
+func ParseRecurrence(input string) (Recurrence, error) {
+
+	trimmed := strings.TrimSpace(input)
+
+	if strings.Contains(strings.ToUpper(trimmed), "FREQ=") {
+		return parseRRule(trimmed)
+	}
+
+	lowered := strings.Join(strings.Fields(strings.ToLower(trimmed)), " ")
+
+	var frequency Frequency
+	var interval, on, afterCompletion string
+
+	if matches := simplePattern.FindStringSubmatch(lowered); matches != nil {
+
+		frequency = map[string]Frequency{"daily": DAILY, "weekly": WEEKLY, "monthly": MONTHLY}[matches[1]]
+		on, afterCompletion = matches[2], matches[3]
+
+	} else if matches := everyPattern.FindStringSubmatch(lowered); matches != nil {
+
+		frequency = map[string]Frequency{"day": DAILY, "week": WEEKLY, "month": MONTHLY}[matches[2]]
+		interval, on, afterCompletion = matches[1], matches[3], matches[4]
+
+	} else {
+		return Recurrence{}, fmt.Errorf("%q %w", input, ErrInvalidRecurrence)
+	}
+
+	recurrence := Recurrence{
+		Frequency:       frequency,
+		Interval:        1,
+		AfterCompletion: afterCompletion != "",
+	}
+
+	if interval != "" {
+		recurrence.Interval, _ = strconv.Atoi(interval)
+	}
+
+	if on != "" {
+
+		if error := recurrence.parseOn(on); error != nil {
+			return Recurrence{}, fmt.Errorf("%q %w", input, error)
+		}
+	}
+
+	if error := recurrence.validate(input); error != nil {
+		return Recurrence{}, error
+	}
+
+	return recurrence, nil
+}
+
+// parseOn reads weekdays for weekly rules and the day of the month for monthly ones.
+func (self *Recurrence) parseOn(on string) error {
+
+	switch self.Frequency {
+	case WEEKLY:
+
+		for _, name := range regexp.MustCompile(`\s*(?:/|,|\band\b)\s*`).Split(on, -1) {
+
+			weekday, ok := dates.ParseWeekday(name)
+
+			if !ok {
+				return fmt.Errorf("%w %s isn't a weekday", ErrInvalidRecurrence, name)
+			}
+
+			self.Weekdays = append(self.Weekdays, weekday)
+		}
+
+	case MONTHLY:
+
+		matches := monthDayPattern.FindStringSubmatch(on)
+
+		if matches == nil {
+			return fmt.Errorf("%w %s isn't a day of the month", ErrInvalidRecurrence, on)
+		}
+
+		self.MonthDay, _ = strconv.Atoi(matches[1])
+
+	default:
+		return fmt.Errorf("%w daily rules can't be on a day", ErrInvalidRecurrence)
+	}
+
+	return nil
+}
+
+func parseRRule(input string) (Recurrence, error) {
+
+	recurrence := Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.ToUpper(input), "RRULE:"), ";") {
+
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+
+		if !found {
+			return Recurrence{}, fmt.Errorf("%q %w", input, ErrInvalidRecurrence)
+		}
+
+		var error error
+
+		switch key {
+		case "FREQ":
+			recurrence.Frequency = Frequency(value)
+		case "INTERVAL":
+			recurrence.Interval, error = strconv.Atoi(value)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+
+				index := slices.Index(byDayNames, day)
+
+				if index == -1 {
+					error = fmt.Errorf("%s isn't a weekday", day)
+					break
+				}
+
+				recurrence.Weekdays = append(recurrence.Weekdays, time.Weekday(index))
+			}
+		case "BYMONTHDAY":
+			recurrence.MonthDay, error = strconv.Atoi(value)
+		case "FROM":
+			recurrence.AfterCompletion = value == "COMPLETION"
+		default:
+			error = fmt.Errorf("%s isn't supported", key)
+		}
+
+		if error != nil {
+			return Recurrence{}, fmt.Errorf("%q %w %w", input, ErrInvalidRecurrence, error)
+		}
+	}
+
+	if error := recurrence.validate(input); error != nil {
+		return Recurrence{}, error
+	}
+
+	return recurrence, nil
+}
+
+func (self *Recurrence) validate(input string) error {
+
+	if !lo.Contains([]Frequency{DAILY, WEEKLY, MONTHLY}, self.Frequency) {
+		return fmt.Errorf("%q %w the frequency must be daily, weekly or monthly", input, ErrInvalidRecurrence)
+	}
+
+	if self.Interval < 1 {
+		return fmt.Errorf("%q %w the interval must be at least 1", input, ErrInvalidRecurrence)
+	}
+
+	if self.MonthDay < 0 || self.MonthDay > 31 {
+		return fmt.Errorf("%q %w the day of the month must be between 1 and 31", input, ErrInvalidRecurrence)
+	}
+
+	if len(self.Weekdays) > 0 && self.Frequency != WEEKLY || self.MonthDay > 0 && self.Frequency != MONTHLY {
+		return fmt.Errorf("%q %w weekdays are for weekly rules and days of the month are for monthly ones", input, ErrInvalidRecurrence)
+	}
+
+	self.Weekdays = lo.Uniq(self.Weekdays)
+	slices.Sort(self.Weekdays)
+
+	return nil
+}
+
+func formatRecurrence(recurrence *Recurrence) string {
+
+	if recurrence == nil {
+		return ""
+	}
+
+	return recurrence.String()
+}
+
+// parseStoredRecurrence drops a rule that was mangled by hand instead of failing the whole read.
+func parseStoredRecurrence(input string) *Recurrence {
+
+	if input == "" {
+		return nil
+	}
+
+	recurrence, error := ParseRecurrence(input)
+
+	if error != nil {
+		return nil
+	}
+
+	return &recurrence
+}
+
+// After returns the first occurrence after from keeping its time of day.
+func (self Recurrence) After(from time.Time) time.Time {
+
+	switch self.Frequency {
+	case WEEKLY:
+
+		if len(self.Weekdays) == 0 {
+			return from.AddDate(0, 0, 7*self.Interval)
+		}
+
+		startOfWeek := from.AddDate(0, 0, -int(from.Weekday()))
+
+		for days := 1; ; days++ {
+
+			candidate := from.AddDate(0, 0, days)
+			weeks := calendarDaysBetween(startOfWeek, candidate) / 7
+
+			if slices.Contains(self.Weekdays, candidate.Weekday()) && weeks%self.Interval == 0 {
+				return candidate
+			}
+		}
+
+	case MONTHLY:
+
+		day := lo.Ternary(self.MonthDay > 0, self.MonthDay, from.Day())
+
+		for months := lo.Ternary(self.MonthDay > 0, 0, self.Interval); ; months += self.Interval {
+
+			candidate := dayOfMonth(from, months, day)
+
+			if candidate.After(from) {
+				return candidate
+			}
+		}
+	}
+
+	return from.AddDate(0, 0, self.Interval)
+}
+
+// calendarDaysBetween ignores the time of day so daylight saving changes don't shift the count.
+func calendarDaysBetween(from, to time.Time) int {
+
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// dayOfMonth moves months ahead of from and picks a day of that month.
+// Days past the end of a short month fall on its last day.
+func dayOfMonth(from time.Time, months, day int) time.Time {
+
+	firstOfMonth := time.Date(from.Year(), from.Month()+time.Month(months), 1, from.Hour(), from.Minute(), from.Second(), 0, from.Location())
+
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// NextDue works out when the next instance is due once the task is completed at now.
+// Tasks without a due date are treated as due at the start of the day.
+// Fixed schedules skip occurrences that already passed so a late task isn't overdue straight away.
+func (self Recurrence) NextDue(due *time.Time, now time.Time) time.Time {
+
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if self.AfterCompletion {
+
+		if due == nil {
+			return self.After(startOfToday)
+		}
+
+		dueAt := due.In(now.Location())
+
+		return self.After(time.Date(now.Year(), now.Month(), now.Day(), dueAt.Hour(), dueAt.Minute(), dueAt.Second(), 0, now.Location()))
+	}
+
+	next := self.After(lo.TernaryF(
+		due == nil,
+		func() time.Time { return startOfToday },
+		func() time.Time { return due.In(now.Location()) },
+	))
+
+	for next.Before(startOfToday) {
+		next = self.After(next)
+	}
+
+	return next
+}
+
+// NextInstance creates the task that replaces a recurring task once it's completed.
+// The rule moves to the new instance so completing the old one again doesn't repeat it twice.
+func (self Task) NextInstance(now time.Time) (Task, bool) {
+
+	if self.Repeat == nil {
+		return Task{}, false
+	}
+
+	next := NewTask(self.Title, self.Description)
+
+	next.Priority = self.Priority
+	next.Tags = slices.Clone(self.Tags)
+	next.ParentId = self.ParentId
+	next.List = self.List
+	next.Repeat = self.Repeat
+	next.Due = lo.ToPtr(self.Repeat.NextDue(self.Due, now))
+
+	return next, true
+}
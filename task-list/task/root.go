@@ -59,8 +59,30 @@ type Task struct {
 	Title, Description, id string
 	createdAt              int64
 	Priority               priority
-	Complete               bool
+	Status                 Status
 	UpdatedAt              time.Time
+	Due                    *time.Time
+	Tags                   []string
+	ParentId               string
+	BlockedBy              []string
+	Repeat                 *Recurrence
+	List                   string
+	Metadata               map[string]string
+	number                 int
+	revision               int
+}
+
+// isInternalKey is true for the metadata an importer keeps for itself like ics:uid or todotxt:priority.
+// They have a colon in their key so they can't clash with a todo.txt extra or a key someone set.
+func isInternalKey(key string) bool {
+
+	return strings.Contains(key, ":")
+}
+
+// UserMetadata is the metadata without the keys importers keep for themselves.
+func (self Task) UserMetadata() map[string]string {
+
+	return lo.OmitBy(self.Metadata, func(key, value string) bool { return isInternalKey(key) })
 }
 
 func NewTask(title, description string) Task {
@@ -70,8 +92,11 @@ func NewTask(title, description string) Task {
 		Description: description,
 		id:          uuid.NewString(),
 		Priority:    LOW,
+		Status:      TODO,
 		createdAt:   time.Now().UnixMicro(),
 		UpdatedAt:   time.Now(),
+		revision:    1,
+		List:        DEFAULT_LIST,
 	}
 }
 
@@ -85,70 +110,146 @@ func (self Task) Id() string {
 	return self.id
 }
 
+// Revision goes up by one every time a task is changed.
+// Tasks stored before revisions existed start at 0.
+func (self Task) Revision() int {
+
+	return self.revision
+}
+
+// Revise marks a task as changed.
+func (self Task) Revise() Task {
+
+	self.revision++
+	self.UpdatedAt = time.Now()
+
+	return self
+}
+
+// Rebase moves a changed task on top of the latest stored revision.
+func (self Task) Rebase(latest Task) Task {
+
+	self.revision = latest.revision + 1
+
+	return self
+}
+
+// Overdue is true for incomplete tasks whose due date has passed.
+// Due is nil for tasks without a due date.
+// A due date at midnight has no time of day so it's due by the end of that day.
+func (self Task) Overdue(now time.Time) bool {
+
+	if self.Due == nil || self.Done() {
+		return false
+	}
+
+	deadline := self.Due.In(now.Location())
+
+	if deadline.Hour() == 0 && deadline.Minute() == 0 && deadline.Second() == 0 {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+
+	return deadline.Before(now)
+}
+
+func (self Task) DueTimeStamp() int64 {
+
+	if self.Due == nil {
+		return 0
+	}
+
+	return self.Due.UnixMicro()
+}
+
 func (self Task) UpdatedAtTimeStamp() int64 {
 	return self.UpdatedAt.UnixMicro()
 }
 
 func (self Task) ToJSON() (string, error) {
 
-	byte, error := json.Marshal(persistedTask{
-		Id:          self.id,
-		Title:       self.Title,
-		Description: self.Description,
-		Priority:    self.Priority.Value(),
-		Complete:    self.Complete,
-		CreatedAt:   self.CreatedAt(),
-		UpdatedAt:   self.UpdatedAtTimeStamp(),
-	})
+	byte, error := json.Marshal(self.toPersistedTask())
 
 	return string(byte), error
 }
 
 func (task Task) ToPrettyJSON() (string, error) {
 
-	byte, error := json.MarshalIndent(persistedTask{
-		Id:          task.id,
-		Title:       task.Title,
-		Description: task.Description,
-		Priority:    task.Priority.Value(),
-		Complete:    task.Complete,
-		CreatedAt:   task.CreatedAt(),
-		UpdatedAt:   task.UpdatedAtTimeStamp(),
-	}, "", "  ")
+	byte, error := json.MarshalIndent(task.toPersistedTask(), "", "  ")
 
 	return string(pretty.Color(pretty.Pretty(byte), nil)), error
 }
 
 type persistedTask struct {
-	Id          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Priority    string `json:"priority"`
-	Complete    bool   `json:"complete"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
-}
-
-const TASK_LIST_STORAGE_PATH = "/home/shelton-louis/Desktop/cli-projects/mini-clis/task-list/task-list.json"
-
-func SaveTasks(tasks []Task) error {
-
-	byte, error := json.Marshal(lo.Map(
-		tasks,
-		func(item Task, index int) persistedTask {
-
-			return persistedTask{
-				Id:          item.id,
-				Title:       item.Title,
-				Description: item.Description,
-				Priority:    item.Priority.Value(),
-				Complete:    item.Complete,
-				CreatedAt:   item.createdAt,
-				UpdatedAt:   item.UpdatedAtTimeStamp(),
-			}
-		},
-	),
-	)
+	Id          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Priority    string            `json:"priority"`
+	Status      string            `json:"status"`
+	Complete    bool              `json:"complete"`
+	CreatedAt   int64             `json:"createdAt"`
+	UpdatedAt   int64             `json:"updatedAt"`
+	Revision    int               `json:"revision"`
+	Due         int64             `json:"due,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	ParentId    string            `json:"parentId,omitempty"`
+	BlockedBy   []string          `json:"blockedBy,omitempty"`
+	Repeat      string            `json:"repeat,omitempty"`
+	List        string            `json:"list,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Number      int               `json:"number,omitempty"`
+}
+
+func (self Task) toPersistedTask() persistedTask {
+
+	return persistedTask{
+		Id:          self.id,
+		Title:       self.Title,
+		Description: self.Description,
+		Priority:    self.Priority.Value(),
+		Status:      self.Status.Value(),
+		Complete:    self.Done(),
+		CreatedAt:   self.createdAt,
+		UpdatedAt:   self.UpdatedAtTimeStamp(),
+		Revision:    self.revision,
+		Due:         self.DueTimeStamp(),
+		Tags:        self.Tags,
+		BlockedBy:   self.BlockedBy,
+		ParentId:    self.ParentId,
+		Repeat:      formatRecurrence(self.Repeat),
+		List:        self.List,
+		Metadata:    self.Metadata,
+		Number:      self.number,
+	}
+}
+
+func (self persistedTask) toTask() Task {
+
+	parsedPriority, _ := ParsePriority(self.Priority)
+
+	return Task{
+		Title:       self.Title,
+		Description: self.Description,
+		Priority:    parsedPriority,
+		Status:      lo.CoalesceOrEmpty(Status(self.Status), lo.Ternary(self.Complete, DONE, TODO)),
+		UpdatedAt:   time.UnixMicro(self.UpdatedAt),
+		createdAt:   self.CreatedAt,
+		id:          self.Id,
+		revision:    self.Revision,
+		Due:         lo.Ternary(self.Due == 0, nil, lo.ToPtr(time.UnixMicro(self.Due))),
+		Tags:        self.Tags,
+		BlockedBy:   self.BlockedBy,
+		ParentId:    self.ParentId,
+		Repeat:      parseStoredRecurrence(self.Repeat),
+		List:        lo.CoalesceOrEmpty(self.List, DEFAULT_LIST),
+		Metadata:    self.Metadata,
+		number:      self.Number,
+	}
+}
+
+// SaveTasks writes tasks inside an envelope stamped with the current schema version.
+func SaveTasks(path string, tasks []Task) error {
+
+	byte, error := encodeDocument(tasks)
 
 	if error != nil {
 
@@ -156,15 +257,17 @@ func SaveTasks(tasks []Task) error {
 
 	}
 
-	return os.WriteFile(TASK_LIST_STORAGE_PATH, byte, os.ModeDevice)
+	return writeFileAtomically(path, byte)
 
 }
 
-func ReadTasks() ([]Task, error) {
+// ReadTasks upgrades files written with an older schema while reading them.
+// Nothing is written back until the tasks are saved.
+func ReadTasks(path string) ([]Task, error) {
 
 	var tasks []Task
 
-	byte, error := os.ReadFile(TASK_LIST_STORAGE_PATH)
+	byte, error := os.ReadFile(path)
 
 	if error != nil {
 
@@ -172,51 +275,25 @@ func ReadTasks() ([]Task, error) {
 
 	}
 
-	var persistedTasks []persistedTask
-
-	unmarshalError := json.Unmarshal(byte, &persistedTasks)
+	version, rawTasks, decodeError := decodeDocument(byte)
 
-	if unmarshalError != nil {
-		return tasks, unmarshalError
+	if decodeError != nil {
+		return tasks, decodeError
 	}
 
-	tasks = lo.Map(persistedTasks, func(item persistedTask, index int) Task {
+	persistedTasks, migrateError := migrateTasks(version, rawTasks)
 
-		parsedPriority, _ := ParsePriority(item.Priority)
-
-		return Task{
-			Title:       item.Title,
-			Description: item.Description,
-			Priority:    parsedPriority,
-			Complete:    item.Complete,
-			UpdatedAt:   time.UnixMicro(item.UpdatedAt),
-			createdAt:   item.CreatedAt,
-			id:          item.Id,
-		}
-
-	})
+	if migrateError != nil {
+		return tasks, migrateError
+	}
 
-	return tasks, nil
+	return fromPersistedTasks(persistedTasks), nil
 
 }
 
 func MarshallTasks(tasks []Task) (string, error) {
 
-	persistedTasks := lo.Map(
-		tasks,
-		func(item Task, index int) persistedTask {
-
-			return persistedTask{
-				Id:          item.id,
-				Title:       item.Title,
-				Description: item.Description,
-				Priority:    item.Priority.Value(),
-				Complete:    item.Complete,
-				CreatedAt:   item.createdAt,
-				UpdatedAt:   item.UpdatedAtTimeStamp(),
-			}
-		},
-	)
+	persistedTasks := toPersistedTasks(tasks)
 
 	byte, error := json.Marshal(&persistedTasks)
 
@@ -229,3 +306,17 @@ func MarshallTasks(tasks []Task) (string, error) {
 	return string(byte), nil
 
 }
+
+func toPersistedTasks(tasks []Task) []persistedTask {
+
+	return lo.Map(tasks, func(item Task, index int) persistedTask {
+		return item.toPersistedTask()
+	})
+}
+
+func fromPersistedTasks(persistedTasks []persistedTask) []Task {
+
+	return lo.Map(persistedTasks, func(item persistedTask, index int) Task {
+		return item.toTask()
+	})
+}
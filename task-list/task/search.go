@@ -0,0 +1,165 @@
+package task
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/samber/lo"
+)
+
+const (
+	TITLE_FIELD       = "title"
+	DESCRIPTION_FIELD = "description"
+)
+
+// A match in the description counts for less than the same match in the title.
+const descriptionWeight = 0.8
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Span is where a search term matched in a field.
+// Start and End are byte offsets so Field[Start:End] is the matched word.
+type Span struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// SearchResult is a task that matched every search term.
+// Score goes from 0 to 1 and 1 means every term is a whole word in the title.
+type SearchResult struct {
+	Task
+	Score   float64
+	Matches []Span
+}
+
+// typosAllowed lets longer terms have more typos so short ones don't match everything.
+func typosAllowed(term string) int {
+
+	return min(len([]rune(term))/3, 2)
+}
+
+// scoreWord says how well a term matches a word.
+// Whole words beat prefixes which beat letters in order which beat typos.
+// It's 0 when they don't match at all.
+func scoreWord(term, word string) float64 {
+
+	switch {
+	case word == term:
+		return 1
+	case strings.HasPrefix(word, term):
+		return 0.9
+	case len(term) > 1 && fuzzy.MatchNormalizedFold(term, word):
+		return 0.5 + 0.3*float64(len(term))/float64(len(word))
+	}
+
+	if distance := fuzzy.LevenshteinDistance(term, word); distance <= typosAllowed(term) {
+		return 0.7 - 0.15*float64(distance)
+	}
+
+	return 0
+}
+
+// bestMatch finds the word in a field a term matches best.
+func bestMatch(term, field, text string, weight float64) (float64, Span) {
+
+	best, bestSpan := 0.0, Span{}
+
+	for _, bounds := range wordPattern.FindAllStringIndex(text, -1) {
+
+		score := scoreWord(term, strings.ToLower(text[bounds[0]:bounds[1]])) * weight
+
+		if score > best {
+			best, bestSpan = score, Span{Field: field, Start: bounds[0], End: bounds[1]}
+		}
+	}
+
+	return best, bestSpan
+}
+
+// SearchTerms splits what was searched for into lower case words.
+func SearchTerms(input string) []string {
+
+	return lo.Map(wordPattern.FindAllString(input, -1), func(term string, index int) string {
+		return strings.ToLower(term)
+	})
+}
+
+// Search keeps the tasks whose title or description matches every term
+// and ranks them by how well they match, best first.
+// Terms can have a typo or two and can skip letters like dply for deploy.
+func Search(tasks []Task, terms []string) []SearchResult {
+
+	results := []SearchResult{}
+
+	if len(terms) == 0 {
+		return results
+	}
+
+	for _, item := range tasks {
+
+		total, matches := 0.0, []Span{}
+
+		for _, term := range terms {
+
+			titleScore, titleSpan := bestMatch(term, TITLE_FIELD, item.Title, 1)
+			descriptionScore, descriptionSpan := bestMatch(term, DESCRIPTION_FIELD, item.Description, descriptionWeight)
+
+			score, span := max(titleScore, descriptionScore), lo.Ternary(titleScore >= descriptionScore, titleSpan, descriptionSpan)
+
+			if score == 0 {
+				total = 0
+				break
+			}
+
+			total += score
+			matches = append(matches, span)
+		}
+
+		if total == 0 {
+			continue
+		}
+
+		results = append(results, SearchResult{
+			Task:    item,
+			Score:   math.Round(total/float64(len(terms))*1000) / 1000,
+			Matches: lo.UniqBy(matches, func(span Span) Span { return span }),
+		})
+	}
+
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.createdAt, b.createdAt))
+	})
+
+	return results
+}
+
+type persistedSearchResult struct {
+	listedTask
+	Score   float64 `json:"score"`
+	Matches []Span  `json:"matches"`
+}
+
+func (self Listing) MarshallSearchResults(results []SearchResult) (string, error) {
+
+	persistedResults := lo.Map(results, func(result SearchResult, index int) persistedSearchResult {
+		return persistedSearchResult{
+			listedTask: self.listedTask(result.Task),
+			Score:      result.Score,
+			Matches:    result.Matches,
+		}
+	})
+
+	byte, error := json.Marshal(&persistedResults)
+
+	if error != nil {
+		return "", error
+	}
+
+	return string(byte), nil
+}
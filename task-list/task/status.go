@@ -0,0 +1,125 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Status is where a task is in the workflow.
+type Status string
+
+// TODO is the status of a task nobody has started.
+const TODO = Status("todo")
+
+// DOING is the status of a task that's being worked on.
+const DOING = Status("doing")
+
+// BLOCKED is the status of a task that can't move until something else happens.
+const BLOCKED = Status("blocked")
+
+// DONE is the status of a completed task.
+const DONE = Status("done")
+
+// DefaultStatuses are the statuses every workflow has in the order tasks move through them.
+var DefaultStatuses = []string{
+	string(TODO),
+	string(DOING),
+	string(BLOCKED),
+	string(DONE),
+}
+
+func (self Status) Value() string {
+
+	return string(self)
+}
+
+// Done is true for completed tasks.
+func (self Status) Done() bool {
+
+	return self == DONE
+}
+
+// Workflow is every status a task can have in the order tasks move through them.
+type Workflow []Status
+
+// NewWorkflow puts the extra statuses from the config between blocked and done.
+// An extra is a single lowercase word that isn't already a status.
+func NewWorkflow(extras []string) (Workflow, error) {
+
+	statuses := lo.Map(DefaultStatuses, func(status string, index int) Status { return Status(status) })
+
+	for _, extra := range extras {
+
+		status, error := parseStoredStatus(extra)
+
+		if error != nil {
+			return nil, error
+		}
+
+		if slices.Contains(statuses, status) {
+			return nil, fmt.Errorf("%s is already a status", status)
+		}
+
+		statuses = slices.Insert(statuses, len(statuses)-1, status)
+	}
+
+	return statuses, nil
+}
+
+func (self Workflow) Values() []string {
+
+	return lo.Map(self, func(status Status, index int) string { return status.Value() })
+}
+
+// Parse checks the input is one of the statuses in the workflow.
+func (self Workflow) Parse(input string) (Status, error) {
+
+	status := Status(strings.ToLower(strings.TrimSpace(input)))
+
+	if !slices.Contains(self, status) {
+
+		return "", fmt.Errorf(
+			"Wrong option %s a status is supposed to be %s",
+			input,
+			strings.Join(self.Values(), ","),
+		)
+	}
+
+	return status, nil
+}
+
+// parseStoredStatus reads a status from an imported file.
+// Files can come from someone with other extra statuses so any single word is kept.
+func parseStoredStatus(input string) (Status, error) {
+
+	status := Status(strings.ToLower(strings.TrimSpace(input)))
+
+	if status == "" || strings.ContainsAny(status.Value(), " \t,") {
+		return "", fmt.Errorf("%q isn't a status a status is a single word", input)
+	}
+
+	return status, nil
+}
+
+// MarkComplete moves a task to done or takes a done task back to todo.
+// A task that isn't done keeps its status when it's marked incomplete.
+func (self Task) MarkComplete(complete bool) Task {
+
+	switch {
+	case complete:
+		self.Status = DONE
+	case self.Status.Done():
+		self.Status = TODO
+	}
+
+	return self
+}
+
+// Done is true for completed tasks.
+func (self Task) Done() bool {
+
+	return self.Status.Done()
+}
@@ -0,0 +1,169 @@
+package task
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// STORAGE_PATH_ENV overrides the config default but not the --store flag.
+const STORAGE_PATH_ENV = "TASK_LIST_FILE"
+
+const STORAGE_FILE_NAME = "task-list.json"
+
+const storageDirName = "task-list"
+
+// DataDir follows the XDG base directory spec.
+// When XDG_DATA_HOME isn't set it falls back to ~/.local/share.
+func DataDir() (string, error) {
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, storageDirName), nil
+	}
+
+	homeDir, error := os.UserHomeDir()
+
+	if error != nil {
+		return "", error
+	}
+
+	return filepath.Join(homeDir, ".local", "share", storageDirName), nil
+
+}
+
+// ResolveStoragePath decides where the task file lives.
+// The first non empty value wins in this order
+// the flag value, TASK_LIST_FILE, the config default and finally the XDG data dir.
+// The file and its parent directories are created when they don't exist.
+func ResolveStoragePath(backend Backend, flagValue, configDefault string) (string, error) {
+
+	path := flagValue
+
+	if path == "" {
+		path = os.Getenv(STORAGE_PATH_ENV)
+	}
+
+	if path == "" {
+		path = configDefault
+	}
+
+	if path == "" {
+
+		dataDir, error := DataDir()
+
+		if error != nil {
+			return "", error
+		}
+
+		path = filepath.Join(dataDir, backend.FileName())
+	}
+
+	path, error := expandHomeDir(path)
+
+	if error != nil {
+		return "", error
+	}
+
+	if error := EnsureStorageFile(backend, path); error != nil {
+		return "", error
+	}
+
+	return path, nil
+
+}
+
+// EnsureStorageFile creates an empty task file at path if there isn't one.
+func EnsureStorageFile(backend Backend, path string) error {
+
+	_, error := os.Stat(path)
+
+	if error == nil {
+		return nil
+	}
+
+	if !errors.Is(error, os.ErrNotExist) {
+		return error
+	}
+
+	if error := os.MkdirAll(filepath.Dir(path), 0o755); error != nil {
+		return error
+	}
+
+	return os.WriteFile(path, backend.emptyContents(), 0o600)
+
+}
+
+func expandHomeDir(path string) (string, error) {
+
+	if path != "~" && !hasHomePrefix(path) {
+		return path, nil
+	}
+
+	homeDir, error := os.UserHomeDir()
+
+	if error != nil {
+		return "", error
+	}
+
+	return filepath.Join(homeDir, path[1:]), nil
+
+}
+
+func hasHomePrefix(path string) bool {
+
+	return len(path) > 1 && path[0] == '~' && path[1] == filepath.Separator
+}
+
+// writeFileAtomically writes to a temp file next to path,
+// fsyncs it and renames it over path.
+// A crash mid write leaves either the old file or the new one never a truncated one.
+func writeFileAtomically(path string, data []byte) error {
+
+	dir := filepath.Dir(path)
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	tempPath := file.Name()
+
+	cleanUp := func(writeError error) error {
+		file.Close()
+		os.Remove(tempPath)
+		return writeError
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return cleanUp(err)
+	}
+
+	if err := file.Chmod(0o600); err != nil {
+		return cleanUp(err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return cleanUp(err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	// Syncing the directory makes the rename itself durable.
+	// Not every platform allows it so it's best effort.
+	if directory, err := os.Open(dir); err == nil {
+		directory.Sync()
+		directory.Close()
+	}
+
+	return nil
+
+}
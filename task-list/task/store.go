@@ -0,0 +1,683 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+var ErrTaskNotFound = errors.New("Task with this id wasn't found")
+
+// TaskStore is how every command reads and writes tasks.
+// Put replaces the task with the same id or inserts it at the front of the list.
+// Save and Put refuse to store tasks that block each other in a cycle.
+// Tasks without a number are numbered in their list when they're stored.
+// Lock guards a read modify write cycle against other processes
+// and returns the function that releases it.
+type TaskStore interface {
+	Load() ([]Task, error)
+	Save(tasks []Task) error
+	Get(id string) (Task, error)
+	Put(task Task) error
+	Delete(ids ...string) error
+	Lock(timeout time.Duration) (func() error, error)
+}
+
+type Backend string
+
+const JSON_BACKEND = Backend("json")
+const JSON_LINES_BACKEND = Backend("jsonl")
+const MEMORY_BACKEND = Backend("memory")
+
+var AllowedBackends = []string{
+	string(JSON_BACKEND),
+	string(JSON_LINES_BACKEND),
+	string(MEMORY_BACKEND),
+}
+
+// ParseBackend treats an empty input as the JSON backend.
+func ParseBackend(input string) (Backend, error) {
+
+	if input == "" {
+		return JSON_BACKEND, nil
+	}
+
+	if !lo.Contains(AllowedBackends, input) {
+
+		return "", fmt.Errorf(
+			"Wrong option %s a backend is supposed to be %s",
+			input,
+			strings.Join(AllowedBackends, ","),
+		)
+	}
+
+	return Backend(input), nil
+
+}
+
+func (self Backend) Value() string {
+
+	return string(self)
+}
+
+func (self Backend) FileName() string {
+
+	if self == JSON_LINES_BACKEND {
+		return "task-list.jsonl"
+	}
+
+	return STORAGE_FILE_NAME
+}
+
+func (self Backend) emptyContents() []byte {
+
+	if self == JSON_LINES_BACKEND {
+		return []byte{}
+	}
+
+	contents, _ := encodeDocument([]Task{})
+
+	return contents
+}
+
+// OpenTaskStore creates the store for a backend.
+// The path is ignored by the memory backend.
+func OpenTaskStore(backend Backend, path string) TaskStore {
+
+	switch backend {
+	case JSON_LINES_BACKEND:
+		return NewJSONLinesStore(path)
+	case MEMORY_BACKEND:
+		return NewMemoryStore()
+	default:
+		return NewJSONStore(path)
+	}
+
+}
+
+func putTask(tasks []Task, task Task) []Task {
+
+	index := slices.IndexFunc(tasks, func(item Task) bool {
+		return item.id == task.id
+	})
+
+	if index == -1 {
+		return slices.Insert(tasks, 0, task)
+	}
+
+	tasks[index] = task
+
+	return tasks
+}
+
+func deleteTasks(tasks []Task, ids []string) []Task {
+
+	return lo.Reject(tasks, func(item Task, index int) bool {
+		return lo.Contains(ids, item.id)
+	})
+}
+
+// SaveChanges puts and deletes tasks with as few writes as it can.
+// Only deleting or putting a single task goes through Delete or Put so the JSON Lines store can append it.
+// Anything else saves every task at once so a failure can't leave half the changes behind.
+func SaveChanges(store TaskStore, puts []Task, deletedIds []string) error {
+
+	switch {
+	case len(puts) == 0 && len(deletedIds) == 0:
+		return nil
+	case len(puts) == 0:
+		return store.Delete(deletedIds...)
+	case len(puts) == 1 && len(deletedIds) == 0:
+		return store.Put(puts[0])
+	}
+
+	tasks, error := store.Load()
+
+	if error != nil {
+		return error
+	}
+
+	for _, task := range puts {
+		tasks = putTask(tasks, task)
+	}
+
+	return store.Save(deleteTasks(tasks, deletedIds))
+}
+
+func findTask(tasks []Task, id string) (Task, error) {
+
+	task, ok := lo.Find(tasks, func(item Task) bool {
+		return item.id == id
+	})
+
+	if !ok {
+		return task, fmt.Errorf("%w %s", ErrTaskNotFound, id)
+	}
+
+	return task, nil
+}
+
+// jsonStore keeps every task in one JSON array.
+type jsonStore struct {
+	path string
+}
+
+func NewJSONStore(path string) TaskStore {
+
+	return jsonStore{path}
+}
+
+// Load numbers tasks written before there were numbers.
+// The numbers are written back the next time the file is saved.
+func (self jsonStore) Load() ([]Task, error) {
+
+	tasks, error := ReadTasks(self.path)
+
+	if error != nil {
+		return nil, error
+	}
+
+	highest, error := readHighestNumbers(self.path)
+
+	if error != nil {
+		return nil, error
+	}
+
+	tasks, _ = numberTasks(tasks, highest)
+
+	return tasks, nil
+}
+
+func (self jsonStore) Save(tasks []Task) error {
+
+	highest, error := readHighestNumbers(self.path)
+
+	if error != nil {
+		return error
+	}
+
+	tasks, _ = numberTasks(tasks, highest)
+
+	if error := ValidateDependencies(tasks); error != nil {
+		return error
+	}
+
+	if error := SaveTasks(self.path, tasks); error != nil {
+		return error
+	}
+
+	return recordHighestNumbers(self.path, tasks)
+}
+
+func (self jsonStore) Get(id string) (Task, error) {
+
+	tasks, error := self.Load()
+
+	if error != nil {
+		return Task{}, error
+	}
+
+	return findTask(tasks, id)
+}
+
+func (self jsonStore) Put(task Task) error {
+
+	tasks, error := self.Load()
+
+	if error != nil {
+		return error
+	}
+
+	return self.Save(putTask(tasks, task))
+}
+
+func (self jsonStore) PlanMigration() (MigrationPlan, error) {
+
+	contents, error := os.ReadFile(self.path)
+
+	if error != nil {
+		return MigrationPlan{}, error
+	}
+
+	version, rawTasks, error := decodeDocument(contents)
+
+	if error != nil {
+		return MigrationPlan{}, error
+	}
+
+	plan := planMigration(version)
+	plan.Tasks = len(rawTasks)
+
+	return plan, nil
+}
+
+func (self jsonStore) Migrate() (MigrationPlan, error) {
+
+	return migrateStore(self)
+}
+
+func (self jsonStore) Lock(timeout time.Duration) (func() error, error) {
+
+	return lockFile(self.path, timeout)
+}
+
+// Delete records the numbers of the deleted tasks first so they aren't given out again.
+func (self jsonStore) Delete(ids ...string) error {
+
+	tasks, error := self.Load()
+
+	if error != nil {
+		return error
+	}
+
+	if error := recordHighestNumbers(self.path, tasks); error != nil {
+		return error
+	}
+
+	return self.Save(deleteTasks(tasks, ids))
+}
+
+const putOperation = "put"
+const deleteOperation = "delete"
+
+// Every put carries the schema version its task was written with
+// so old records are migrated one by one when they're replayed.
+type jsonLinesRecord struct {
+	Operation     string          `json:"op"`
+	SchemaVersion int             `json:"schemaVersion,omitempty"`
+	Task          json.RawMessage `json:"task,omitempty"`
+	Id            string          `json:"id,omitempty"`
+}
+
+func newPutRecord(task Task) (jsonLinesRecord, error) {
+
+	rawTask, error := json.Marshal(task.toPersistedTask())
+
+	return jsonLinesRecord{
+		Operation:     putOperation,
+		SchemaVersion: CurrentSchemaVersion(),
+		Task:          rawTask,
+	}, error
+}
+
+// jsonLinesStore appends one record per change instead of rewriting the file.
+// Load replays every record so the latest put or delete for an id wins.
+// Save compacts the file down to one put per task.
+type jsonLinesStore struct {
+	path string
+}
+
+func NewJSONLinesStore(path string) TaskStore {
+
+	return jsonLinesStore{path}
+}
+
+func (self jsonLinesStore) Load() ([]Task, error) {
+
+	tasks, error := self.replayTasks()
+
+	if error != nil {
+		return nil, error
+	}
+
+	highest, error := readHighestNumbers(self.path)
+
+	if error != nil {
+		return nil, error
+	}
+
+	tasks, _ = numberTasks(tasks, highest)
+
+	return tasks, nil
+}
+
+// replayTasks rebuilds the tasks without numbering them
+// so writes can tell whether there are numbers to write back.
+func (self jsonLinesStore) replayTasks() ([]Task, error) {
+
+	var tasks []Task
+
+	error := self.replay(func(record jsonLinesRecord) error {
+
+		switch record.Operation {
+		case putOperation:
+
+			persisted, error := migrateTask(record.SchemaVersion, record.Task)
+
+			if error != nil {
+				return error
+			}
+
+			tasks = putTask(tasks, persisted.toTask())
+		case deleteOperation:
+			tasks = deleteTasks(tasks, []string{record.Id})
+		}
+
+		return nil
+	})
+
+	return tasks, error
+
+}
+
+func (self jsonLinesStore) replay(apply func(record jsonLinesRecord) error) error {
+
+	contents, error := os.ReadFile(self.path)
+
+	if error != nil {
+		return error
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var record jsonLinesRecord
+
+		if error := json.Unmarshal(scanner.Bytes(), &record); error != nil {
+			return fmt.Errorf("%s:%d %w", self.path, line, error)
+		}
+
+		if !lo.Contains([]string{putOperation, deleteOperation}, record.Operation) ||
+			record.Operation == putOperation && record.Task == nil {
+			return fmt.Errorf("%s:%d unknown record %s", self.path, line, record.Operation)
+		}
+
+		if error := apply(record); error != nil {
+			return fmt.Errorf("%s:%d %w", self.path, line, error)
+		}
+	}
+
+	return scanner.Err()
+
+}
+
+// PlanMigration starts from the oldest schema version found in the file.
+func (self jsonLinesStore) PlanMigration() (MigrationPlan, error) {
+
+	oldestVersion := CurrentSchemaVersion()
+	puts := 0
+
+	error := self.replay(func(record jsonLinesRecord) error {
+
+		if record.Operation == putOperation {
+			puts++
+			oldestVersion = min(oldestVersion, record.SchemaVersion)
+		}
+
+		return nil
+	})
+
+	plan := planMigration(oldestVersion)
+	plan.Tasks = puts
+
+	return plan, error
+}
+
+func (self jsonLinesStore) Migrate() (MigrationPlan, error) {
+
+	return migrateStore(self)
+}
+
+// Save writes the oldest task first so replaying the puts rebuilds the same order.
+func (self jsonLinesStore) Save(tasks []Task) error {
+
+	highest, error := readHighestNumbers(self.path)
+
+	if error != nil {
+		return error
+	}
+
+	tasks, _ = numberTasks(tasks, highest)
+
+	if error := ValidateDependencies(tasks); error != nil {
+		return error
+	}
+
+	var buffer bytes.Buffer
+
+	for _, task := range slices.Backward(tasks) {
+
+		record, error := newPutRecord(task)
+
+		if error != nil {
+			return error
+		}
+
+		if error := writeJSONLine(&buffer, record); error != nil {
+			return error
+		}
+	}
+
+	if error := writeFileAtomically(self.path, buffer.Bytes()); error != nil {
+		return error
+	}
+
+	return recordHighestNumbers(self.path, tasks)
+}
+
+func (self jsonLinesStore) Get(id string) (Task, error) {
+
+	tasks, error := self.Load()
+
+	if error != nil {
+		return Task{}, error
+	}
+
+	return findTask(tasks, id)
+}
+
+// Put appends the task unless older tasks still need numbers
+// in which case the file is compacted so the numbers are written once.
+func (self jsonLinesStore) Put(task Task) error {
+
+	storedTasks, error := self.replayTasks()
+
+	if error != nil {
+		return error
+	}
+
+	highest, error := readHighestNumbers(self.path)
+
+	if error != nil {
+		return error
+	}
+
+	tasks, numbered := numberTasks(storedTasks, highest)
+
+	tasks = putNumberedTask(tasks, task, highest)
+
+	if numbered {
+		return self.Save(tasks)
+	}
+
+	if error := ValidateDependencies(tasks); error != nil {
+		return error
+	}
+
+	storedTask, _ := findTask(tasks, task.id)
+
+	record, error := newPutRecord(storedTask)
+
+	if error != nil {
+		return error
+	}
+
+	if error := self.append(record); error != nil {
+		return error
+	}
+
+	return recordHighestNumbers(self.path, tasks)
+}
+
+func (self jsonLinesStore) Delete(ids ...string) error {
+
+	storedTasks, error := self.replayTasks()
+
+	if error != nil {
+		return error
+	}
+
+	highest, error := readHighestNumbers(self.path)
+
+	if error != nil {
+		return error
+	}
+
+	tasks, numbered := numberTasks(storedTasks, highest)
+
+	// The numbers of the deleted tasks are recorded first so they aren't given out again.
+	if error := recordHighestNumbers(self.path, tasks); error != nil {
+		return error
+	}
+
+	if numbered {
+		return self.Save(deleteTasks(tasks, ids))
+	}
+
+	return self.append(lo.Map(ids, func(id string, index int) jsonLinesRecord {
+		return jsonLinesRecord{Operation: deleteOperation, Id: id}
+	})...)
+}
+
+func (self jsonLinesStore) Lock(timeout time.Duration) (func() error, error) {
+
+	return lockFile(self.path, timeout)
+}
+
+func (self jsonLinesStore) append(records ...jsonLinesRecord) error {
+
+	var buffer bytes.Buffer
+
+	for _, record := range records {
+
+		if error := writeJSONLine(&buffer, record); error != nil {
+			return error
+		}
+	}
+
+	file, error := os.OpenFile(self.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+
+	if error != nil {
+		return error
+	}
+
+	if _, error := file.Write(buffer.Bytes()); error != nil {
+		file.Close()
+		return error
+	}
+
+	if error := file.Sync(); error != nil {
+		file.Close()
+		return error
+	}
+
+	return file.Close()
+}
+
+func writeJSONLine(buffer *bytes.Buffer, record jsonLinesRecord) error {
+
+	byte, error := json.Marshal(record)
+
+	if error != nil {
+		return error
+	}
+
+	buffer.Write(byte)
+	buffer.WriteByte('\n')
+
+	return nil
+}
+
+// memoryStore never touches the disk.
+// It's useful for tests and dry runs.
+type memoryStore struct {
+	tasks   *[]Task
+	highest map[string]int
+}
+
+func NewMemoryStore(tasks ...Task) TaskStore {
+
+	copiedTasks, _ := numberTasks(slices.Clone(tasks), nil)
+
+	return memoryStore{&copiedTasks, highestNumbers(copiedTasks)}
+}
+
+// record keeps the highest numbers in memory like the other stores keep them in the list registry.
+func (self memoryStore) record(tasks []Task) {
+
+	for list, number := range highestNumbers(tasks) {
+		self.highest[list] = max(self.highest[list], number)
+	}
+}
+
+func (self memoryStore) Load() ([]Task, error) {
+
+	return slices.Clone(*self.tasks), nil
+}
+
+func (self memoryStore) Save(tasks []Task) error {
+
+	tasks, _ = numberTasks(tasks, self.highest)
+
+	if error := ValidateDependencies(tasks); error != nil {
+		return error
+	}
+
+	*self.tasks = slices.Clone(tasks)
+
+	self.record(tasks)
+
+	return nil
+}
+
+func (self memoryStore) Get(id string) (Task, error) {
+
+	return findTask(*self.tasks, id)
+}
+
+func (self memoryStore) Put(task Task) error {
+
+	tasks := putNumberedTask(slices.Clone(*self.tasks), task, self.highest)
+
+	if error := ValidateDependencies(tasks); error != nil {
+		return error
+	}
+
+	*self.tasks = tasks
+
+	self.record(tasks)
+
+	return nil
+}
+
+func (self memoryStore) Delete(ids ...string) error {
+
+	*self.tasks = deleteTasks(*self.tasks, ids)
+
+	return nil
+}
+
+// Lock has nothing to guard because a memory store is never shared between processes.
+func (self memoryStore) Lock(timeout time.Duration) (func() error, error) {
+
+	return func() error { return nil }, nil
+}
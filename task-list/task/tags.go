@@ -0,0 +1,91 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var tagPattern = regexp.MustCompile(`^[^\s,]+$`)
+
+// ParseTag trims a tag and makes sure it's a single word without commas.
+func ParseTag(input string) (string, error) {
+
+	tag := strings.TrimSpace(input)
+
+	if !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("Wrong tag %q a tag can't be empty or contain spaces or commas", input)
+	}
+
+	return tag, nil
+}
+
+// ParseTags parses every tag and drops duplicates.
+func ParseTags(inputs []string) ([]string, error) {
+
+	tags := make([]string, 0, len(inputs))
+
+	for _, input := range inputs {
+
+		tag, error := ParseTag(input)
+
+		if error != nil {
+			return nil, error
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return lo.Uniq(tags), nil
+}
+
+func (self Task) HasTag(tag string) bool {
+
+	return lo.Contains(self.Tags, tag)
+}
+
+// AddTags returns the tags with the new ones appended in order.
+func (self Task) AddTags(tags ...string) []string {
+
+	return lo.Uniq(append(slices.Clone(self.Tags), tags...))
+}
+
+func (self Task) RemoveTags(tags ...string) []string {
+
+	return lo.Without(self.Tags, tags...)
+}
+
+const ANY = "any"
+const ALL = "all"
+
+var AllowedTagMatches = []string{ANY, ALL}
+
+// MatchesTags reports whether a task has any or all of the tags.
+// No tags always matches.
+func (self Task) MatchesTags(tags []string, match string) bool {
+
+	if len(tags) == 0 {
+		return true
+	}
+
+	if match == ALL {
+		return lo.EveryBy(tags, self.HasTag)
+	}
+
+	return lo.SomeBy(tags, self.HasTag)
+}
+
+// AllTags lists every tag used by the tasks sorted alphabetically.
+func AllTags(tasks []Task) []string {
+
+	tags := lo.Uniq(lo.FlatMap(tasks, func(item Task, index int) []string {
+		return item.Tags
+	}))
+
+	slices.Sort(tags)
+
+	return tags
+}
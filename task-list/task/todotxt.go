@@ -0,0 +1,383 @@
+package task
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const TODOTXT = "todotxt"
+
+// todo.txt has no way to say a line had no priority or dates while a task always has them.
+// These keys remember it so exporting gives back the same line.
+// The prefix and extras keys keep the text before and after the title when it isn't written
+// the way an export writes it, like extras in another order or a priority on a completed line.
+// A todo.txt key can't contain a colon so they never clash with a key:value extra.
+const (
+	todoTxtPriorityKey  = "todotxt:priority"
+	todoTxtCreatedKey   = "todotxt:created"
+	todoTxtCompletedKey = "todotxt:completed"
+	todoTxtPrefixKey    = "todotxt:prefix"
+	todoTxtExtrasKey    = "todotxt:extras"
+)
+
+// DUE_KEY is the key:value extra todo.txt apps use for due dates.
+const DUE_KEY = "due"
+
+// PRIORITY_KEY is where todo.txt apps keep the priority of a completed task.
+const PRIORITY_KEY = "pri"
+
+const todoTxtDate = "2006-01-02"
+
+var todoTxtDueFormats = []string{todoTxtDate, "2006-01-02T15:04"}
+
+var (
+	todoTxtPriorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtExtraPattern    = regexp.MustCompile(`^\pL[^\s:]*:[^\s/]\S*$`)
+	todoTxtWordPattern     = regexp.MustCompile(`\S+`)
+)
+
+// todoTxtParts is a todo.txt line split around its title.
+// The prefix has the completion mark, priority and dates and the extras are the ones after the title.
+// Each part keeps the spaces that separate it from the title so joining them gives back the line.
+type todoTxtParts struct {
+	prefix, title, extras string
+}
+
+func (self todoTxtParts) line() string {
+
+	return self.prefix + self.title + self.extras
+}
+
+var todoTxtPriorities = map[string]priority{
+	"A": HIGH,
+	"B": MEDIUM,
+	"C": LOW,
+}
+
+func isTodoTxtTag(word string) bool {
+
+	return len(word) > 1 && (word[0] == '+' || word[0] == '@')
+}
+
+func isTodoTxtExtra(word string) bool {
+
+	return isTodoTxtTag(word) || todoTxtExtraPattern.MatchString(word)
+}
+
+func parseTodoTxtDate(input string) (time.Time, bool) {
+
+	date, error := time.ParseInLocation(todoTxtDate, input, time.Local)
+
+	return date, error == nil && todoTxtDatePattern.MatchString(input)
+}
+
+func parseTodoTxtPriority(letter string, item *Task) {
+
+	if parsedPriority, ok := todoTxtPriorities[letter]; ok {
+		item.Priority = parsedPriority
+		return
+	}
+
+	item.Priority = LOW
+	item.Metadata[todoTxtPriorityKey] = letter
+}
+
+// ParseTodoTxtLine reads one todo.txt line into a task for list.
+// +projects become tags and @contexts become tags that keep their @.
+// key:value extras go in Metadata apart from due which sets Due.
+// Extras in the middle of the text stay in the title so the line reads the same when it's exported.
+func ParseTodoTxtLine(line, list string) Task {
+
+	item, parts := parseTodoTxtParts(line, list)
+
+	written := item.todoTxtParts()
+
+	if parts.prefix != written.prefix {
+		item.Metadata[todoTxtPrefixKey] = parts.prefix
+	}
+
+	if parts.extras != written.extras {
+		item.Metadata[todoTxtExtrasKey] = parts.extras
+	}
+
+	if len(item.Metadata) == 0 {
+		item.Metadata = nil
+	}
+
+	return item
+}
+
+// parseTodoTxtParts reads a line into a task and the text around its title as it was written.
+func parseTodoTxtParts(line, list string) (Task, todoTxtParts) {
+
+	spans := todoTxtWordPattern.FindAllStringIndex(line, -1)
+
+	words := lo.Map(spans, func(span []int, index int) string { return line[span[0]:span[1]] })
+
+	item := NewTask("", "")
+	item.List = list
+	item.Metadata = map[string]string{todoTxtPriorityKey: ""}
+
+	if len(words) > 0 && words[0] == "x" {
+		item.Status = DONE
+		words = words[1:]
+	}
+
+	if len(words) > 0 {
+		if matches := todoTxtPriorityPattern.FindStringSubmatch(words[0]); matches != nil {
+			delete(item.Metadata, todoTxtPriorityKey)
+			parseTodoTxtPriority(matches[1], &item)
+			words = words[1:]
+		}
+	}
+
+	dates := []time.Time{}
+
+	for len(words) > 0 && len(dates) < lo.Ternary(item.Done(), 2, 1) {
+
+		date, ok := parseTodoTxtDate(words[0])
+
+		if !ok {
+			break
+		}
+
+		dates = append(dates, date)
+		words = words[1:]
+	}
+
+	// A completed line has its completion date first and its creation date second.
+	if item.Done() {
+
+		if len(dates) == 0 {
+			item.Metadata[todoTxtCompletedKey] = ""
+		} else {
+			item.UpdatedAt = dates[0]
+			dates = dates[1:]
+		}
+	}
+
+	if len(dates) == 0 {
+		item.Metadata[todoTxtCreatedKey] = ""
+	} else {
+		item.createdAt = dates[0].UnixMicro()
+
+		if !item.Done() {
+			item.UpdatedAt = dates[0]
+		}
+	}
+
+	// The extras at the end of the line come off the title.
+	end := len(words)
+
+	for end > 0 && isTodoTxtExtra(words[end-1]) {
+		end--
+	}
+
+	// The title keeps its spaces and the parts keep everything around it.
+	titleStart, titleEnd := len(line), len(line)
+
+	if first := len(spans) - len(words); first < len(spans) {
+		titleStart, titleEnd = spans[first][0], spans[first][0]
+
+		if end > 0 {
+			titleEnd = spans[first+end-1][1]
+		}
+	}
+
+	item.Title = line[titleStart:titleEnd]
+
+	for _, word := range words {
+
+		switch {
+		case !isTodoTxtExtra(word):
+			continue
+
+		case isTodoTxtTag(word):
+			item.Tags = item.AddTags(strings.TrimPrefix(word, "+"))
+
+		default:
+			key, value, _ := strings.Cut(word, ":")
+
+			if due, ok := parseTodoTxtDue(key, value); ok {
+				item.Due = &due
+				continue
+			}
+
+			// Completed lines keep their priority as pri:A.
+			if key == PRIORITY_KEY && item.Done() && lo.HasKey(item.Metadata, todoTxtPriorityKey) && len(value) == 1 && value >= "A" && value <= "Z" {
+				delete(item.Metadata, todoTxtPriorityKey)
+				parseTodoTxtPriority(value, &item)
+				continue
+			}
+
+			item.Metadata[key] = value
+		}
+	}
+
+	return item, todoTxtParts{prefix: line[:titleStart], title: item.Title, extras: line[titleEnd:]}
+}
+
+func parseTodoTxtDue(key, value string) (time.Time, bool) {
+
+	if key != DUE_KEY {
+		return time.Time{}, false
+	}
+
+	for _, format := range todoTxtDueFormats {
+		if due, error := time.ParseInLocation(format, value, time.Local); error == nil {
+			return due, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// ReadTodoTxt reads every line of a todo.txt file into tasks for list skipping blank lines.
+func ReadTodoTxt(reader io.Reader, list string) ([]Task, error) {
+
+	tasks := []Task{}
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		tasks = append(tasks, ParseTodoTxtLine(scanner.Text(), list))
+	}
+
+	return tasks, scanner.Err()
+}
+
+// TodoTxtLine writes a task as a todo.txt line.
+// Extras already in the title aren't written again.
+// The ones at the end are the tags, then due, then the other key:value extras by key.
+// A task read from todo.txt gets back the text around its title as it was written
+// as long as that text still says the same as the task.
+func (self Task) TodoTxtLine() string {
+
+	written := self.todoTxtParts()
+
+	prefixes := []string{written.prefix}
+	extras := []string{written.extras}
+
+	if prefix, ok := self.Metadata[todoTxtPrefixKey]; ok {
+		prefixes = []string{prefix, written.prefix}
+	}
+
+	if extra, ok := self.Metadata[todoTxtExtrasKey]; ok {
+		extras = []string{extra, written.extras}
+	}
+
+	for _, prefix := range prefixes {
+		for _, extra := range extras {
+
+			parts := todoTxtParts{prefix: prefix, title: self.Title, extras: extra}
+
+			reread, _ := parseTodoTxtParts(parts.line(), self.List)
+
+			if reread.Title == self.Title && reread.todoTxtParts() == written {
+				return parts.line()
+			}
+		}
+	}
+
+	return written.line()
+}
+
+// todoTxtParts are the parts of the line an export writes for a task.
+func (self Task) todoTxtParts() todoTxtParts {
+
+	words := []string{}
+
+	letter, _ := lo.FindKeyBy(todoTxtPriorities, func(key string, value priority) bool {
+		return value == self.Priority
+	})
+
+	if original, ok := self.Metadata[todoTxtPriorityKey]; ok && self.Priority == LOW {
+		letter = original
+	}
+
+	if self.Done() {
+
+		words = append(words, "x")
+
+		if _, ok := self.Metadata[todoTxtCompletedKey]; !ok {
+			words = append(words, self.UpdatedAt.In(time.Local).Format(todoTxtDate))
+		}
+	} else if letter != "" {
+		words = append(words, "("+letter+")")
+	}
+
+	if _, ok := self.Metadata[todoTxtCreatedKey]; !ok {
+		words = append(words, time.UnixMicro(self.createdAt).In(time.Local).Format(todoTxtDate))
+	}
+
+	inTitle := strings.Fields(self.Title)
+
+	extras := lo.Map(self.Tags, func(tag string, index int) string {
+		return lo.Ternary(isTodoTxtTag(tag), tag, "+"+tag)
+	})
+
+	if self.Due != nil {
+
+		due := self.Due.In(time.Local)
+
+		extras = append(extras, DUE_KEY+":"+due.Format(lo.Ternary(
+			due.Hour() == 0 && due.Minute() == 0,
+			todoTxtDueFormats[0],
+			todoTxtDueFormats[1],
+		)))
+	}
+
+	if self.Done() && letter != "" {
+		extras = append(extras, PRIORITY_KEY+":"+letter)
+	}
+
+	userMetadata := self.UserMetadata()
+
+	for _, key := range slices.Sorted(maps.Keys(userMetadata)) {
+		extras = append(extras, key+":"+userMetadata[key])
+	}
+
+	extras = lo.Filter(extras, func(extra string, index int) bool { return !lo.Contains(inTitle, extra) })
+
+	parts := todoTxtParts{
+		prefix: strings.Join(words, " "),
+		title:  self.Title,
+		extras: strings.Join(extras, " "),
+	}
+
+	if parts.prefix != "" && (parts.title != "" || parts.extras != "") {
+		parts.prefix += " "
+	}
+
+	if parts.extras != "" && parts.title != "" {
+		parts.extras = " " + parts.extras
+	}
+
+	return parts
+}
+
+// WriteTodoTxt writes one todo.txt line for every task.
+func WriteTodoTxt(writer io.Writer, tasks []Task) error {
+
+	for _, item := range tasks {
+
+		if _, error := io.WriteString(writer, item.TodoTxtLine()+"\n"); error != nil {
+			return error
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,28 @@
 package main_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/mini-clis/task-list/config"
+	"github.com/mini-clis/task-list/task"
 	. "github.com/onsi/ginkgo/v2"
 )
 
+var storagePath string
+
 func TestTaskList(t *testing.T) {
+	storageDir := t.TempDir()
+
+	storagePath = filepath.Join(storageDir, task.STORAGE_FILE_NAME)
+
+	t.Setenv(task.STORAGE_PATH_ENV, storagePath)
+	t.Setenv(config.CONFIG_PATH_ENV, filepath.Join(storageDir, config.CONFIG_FILE_NAME))
+
+	if err := os.WriteFile(storagePath, []byte("[]"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
 	RunSpecs(t, "TaskList Suite")
 }